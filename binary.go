@@ -0,0 +1,329 @@
+package citytimezones
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Binary dataset layout (version 1). All integers are varints.
+//
+//	magic    "CTZB" followed by the version byte
+//	count    number of cities
+//	strings  number of strings, their byte lengths, then the concatenated bytes
+//	columns  one column per CityData field, each holding count values:
+//	         string fields as string table indexes, coordinates as fixed-point
+//	         integers (degrees * coordScale), and loosely-typed interface{}
+//	         fields as a kind byte followed by the value
+//
+// Every string (names, countries, provinces, zones) is stored once in the
+// table, and decoding needs no reflection.
+const (
+	binaryMagic   = "CTZB"
+	binaryVersion = 1
+
+	// coordScale keeps the 9 decimal places used by the upstream data exact
+	coordScale = 1e9
+)
+
+// Kinds of loosely-typed field values
+const (
+	kindNull   = 0
+	kindString = 1
+	kindInt    = 2 // integral number, decodes as float64
+	kindFloat  = 3 // float64 bits
+)
+
+var errCorruptBinary = errors.New("corrupt binary dataset")
+
+// minCityBytes is the smallest encoding of a city: six string indexes, two
+// coordinates and six value kinds of one byte each
+const minCityBytes = 14
+
+// isBinaryDataset reports whether data starts with the binary dataset magic
+func isBinaryDataset(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(binaryMagic))
+}
+
+// EncodeBinary serializes cities in the compact binary dataset format read by
+// DecodeBinary. Numbers in interface{} fields decode as float64, the same as
+// they do from JSON.
+func EncodeBinary(cities []CityData) ([]byte, error) {
+	enc := binaryEncoder{index: make(map[string]uint64)}
+
+	// Intern strings first so the table can precede the columns
+	for _, c := range cities {
+		for _, s := range []string{c.City, c.CityAscii, c.Country, c.Province, c.Timezone, c.ID} {
+			enc.intern(s)
+		}
+		for _, v := range []interface{}{c.ISO2, c.ISO3, c.StateAnsi, c.ExactCity, c.ExactProvince, c.Pop} {
+			if s, ok := v.(string); ok {
+				enc.intern(s)
+			}
+		}
+	}
+
+	buf := make([]byte, 0, 64*len(cities))
+	buf = append(buf, binaryMagic...)
+	buf = append(buf, binaryVersion)
+	buf = binary.AppendUvarint(buf, uint64(len(cities)))
+
+	buf = binary.AppendUvarint(buf, uint64(len(enc.strings)))
+	for _, s := range enc.strings {
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+	}
+	for _, s := range enc.strings {
+		buf = append(buf, s...)
+	}
+
+	stringColumns := []func(CityData) string{
+		func(c CityData) string { return c.City },
+		func(c CityData) string { return c.CityAscii },
+		func(c CityData) string { return c.Country },
+		func(c CityData) string { return c.Province },
+		func(c CityData) string { return c.Timezone },
+		func(c CityData) string { return c.ID },
+	}
+	for _, column := range stringColumns {
+		for _, c := range cities {
+			buf = binary.AppendUvarint(buf, enc.index[column(c)])
+		}
+	}
+
+	for _, coord := range []func(CityData) float64{
+		func(c CityData) float64 { return c.Lat },
+		func(c CityData) float64 { return c.Lng },
+	} {
+		for _, c := range cities {
+			v := coord(c)
+			fixed := int64(math.Round(v * coordScale))
+			if float64(fixed)/coordScale != v {
+				return nil, fmt.Errorf("coordinate %v of %s has more than 9 decimal places", v, c.City)
+			}
+			buf = binary.AppendVarint(buf, fixed)
+		}
+	}
+
+	valueColumns := []func(CityData) interface{}{
+		func(c CityData) interface{} { return c.Pop },
+		func(c CityData) interface{} { return c.ISO2 },
+		func(c CityData) interface{} { return c.ISO3 },
+		func(c CityData) interface{} { return c.StateAnsi },
+		func(c CityData) interface{} { return c.ExactCity },
+		func(c CityData) interface{} { return c.ExactProvince },
+	}
+	for _, column := range valueColumns {
+		for _, c := range cities {
+			var err error
+			if buf, err = enc.appendValue(buf, column(c)); err != nil {
+				return nil, fmt.Errorf("%s: %w", c.City, err)
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+type binaryEncoder struct {
+	strings []string
+	index   map[string]uint64
+}
+
+func (e *binaryEncoder) intern(s string) {
+	if _, ok := e.index[s]; !ok {
+		e.index[s] = uint64(len(e.strings))
+		e.strings = append(e.strings, s)
+	}
+}
+
+func (e *binaryEncoder) appendValue(buf []byte, v interface{}) ([]byte, error) {
+	var f float64
+	switch v := v.(type) {
+	case nil:
+		return append(buf, kindNull), nil
+	case string:
+		buf = append(buf, kindString)
+		return binary.AppendUvarint(buf, e.index[v]), nil
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		buf = append(buf, kindInt)
+		return binary.AppendVarint(buf, int64(f)), nil
+	}
+	buf = append(buf, kindFloat)
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+}
+
+// DecodeBinary reads a dataset written by EncodeBinary
+func DecodeBinary(data []byte) ([]CityData, error) {
+	if !isBinaryDataset(data) || len(data) < len(binaryMagic)+1 {
+		return nil, fmt.Errorf("%w: missing %s header", errCorruptBinary, binaryMagic)
+	}
+	if version := data[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("unsupported binary dataset version %d", version)
+	}
+
+	dec := binaryDecoder{data: data, pos: len(binaryMagic) + 1}
+	count := dec.uvarint()
+	numStrings := dec.uvarint()
+	if dec.err != nil || count > uint64(len(data)-dec.pos)/minCityBytes || numStrings > uint64(len(data)-dec.pos) {
+		return nil, errCorruptBinary
+	}
+
+	// Copy the string table once; individual strings share its memory.
+	// Each length is checked against the bytes left before it is added, so
+	// the total cannot overflow.
+	lengths := make([]uint64, numStrings)
+	total := uint64(0)
+	for i := range lengths {
+		lengths[i] = dec.uvarint()
+		remaining := uint64(len(data) - dec.pos)
+		if dec.err != nil || total > remaining || lengths[i] > remaining-total {
+			return nil, errCorruptBinary
+		}
+		total += lengths[i]
+	}
+	blob := string(data[dec.pos : dec.pos+int(total)])
+	dec.pos += int(total)
+
+	dec.strings = make([]string, numStrings)
+	offset := uint64(0)
+	for i, n := range lengths {
+		dec.strings[i] = blob[offset : offset+n]
+		offset += n
+	}
+
+	cities := make([]CityData, count)
+	for _, field := range []func(*CityData) *string{
+		func(c *CityData) *string { return &c.City },
+		func(c *CityData) *string { return &c.CityAscii },
+		func(c *CityData) *string { return &c.Country },
+		func(c *CityData) *string { return &c.Province },
+		func(c *CityData) *string { return &c.Timezone },
+		func(c *CityData) *string { return &c.ID },
+	} {
+		for i := range cities {
+			*field(&cities[i]) = dec.string()
+		}
+	}
+
+	for _, field := range []func(*CityData) *float64{
+		func(c *CityData) *float64 { return &c.Lat },
+		func(c *CityData) *float64 { return &c.Lng },
+	} {
+		for i := range cities {
+			*field(&cities[i]) = float64(dec.varint()) / coordScale
+		}
+	}
+
+	for _, field := range []func(*CityData) *interface{}{
+		func(c *CityData) *interface{} { return &c.Pop },
+		func(c *CityData) *interface{} { return &c.ISO2 },
+		func(c *CityData) *interface{} { return &c.ISO3 },
+		func(c *CityData) *interface{} { return &c.StateAnsi },
+		func(c *CityData) *interface{} { return &c.ExactCity },
+		func(c *CityData) *interface{} { return &c.ExactProvince },
+	} {
+		for i := range cities {
+			*field(&cities[i]) = dec.value()
+		}
+	}
+
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	if dec.pos != len(data) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", errCorruptBinary, len(data)-dec.pos)
+	}
+	return cities, nil
+}
+
+// binaryDecoder reads varint-encoded values, remembering the first error so
+// that column loops stay free of error checks
+type binaryDecoder struct {
+	data    []byte
+	pos     int
+	strings []string
+	err     error
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		d.err = errCorruptBinary
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		d.err = errCorruptBinary
+		return 0
+	}
+	d.pos += n
+	return v
+}
+
+func (d *binaryDecoder) string() string {
+	i := d.uvarint()
+	if i >= uint64(len(d.strings)) {
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: string index %d out of range", errCorruptBinary, i)
+		}
+		return ""
+	}
+	return d.strings[i]
+}
+
+func (d *binaryDecoder) value() interface{} {
+	if d.err != nil {
+		return nil
+	}
+	if d.pos >= len(d.data) {
+		d.err = errCorruptBinary
+		return nil
+	}
+	kind := d.data[d.pos]
+	d.pos++
+
+	switch kind {
+	case kindNull:
+		return nil
+	case kindString:
+		return d.string()
+	case kindInt:
+		return float64(d.varint())
+	case kindFloat:
+		if len(d.data)-d.pos < 8 {
+			d.err = errCorruptBinary
+			return nil
+		}
+		bits := binary.LittleEndian.Uint64(d.data[d.pos:])
+		d.pos += 8
+		return math.Float64frombits(bits)
+	default:
+		d.err = fmt.Errorf("%w: unknown value kind %d", errCorruptBinary, kind)
+		return nil
+	}
+}
@@ -0,0 +1,171 @@
+package citytimezones
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// readJSONCities loads data/cityMap.json, the source of every embedded variant
+func readJSONCities(tb testing.TB) []CityData {
+	data, err := os.ReadFile("data/cityMap.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var cities []CityData
+	if err := json.Unmarshal(data, &cities); err != nil {
+		tb.Fatal(err)
+	}
+	return cities
+}
+
+func TestBinary_RoundTripMatchesJSON(t *testing.T) {
+	cities := readJSONCities(t)
+
+	encoded, err := EncodeBinary(cities)
+	if err != nil {
+		t.Fatalf("Expected cities to encode, got error: %v", err)
+	}
+	decoded, err := DecodeBinary(encoded)
+	if err != nil {
+		t.Fatalf("Expected cities to decode, got error: %v", err)
+	}
+
+	if len(decoded) != len(cities) {
+		t.Fatalf("Expected %d cities, got %d", len(cities), len(decoded))
+	}
+	for i := range cities {
+		if !reflect.DeepEqual(cities[i], decoded[i]) {
+			t.Fatalf("City %d differs after round trip:\n  json:   %#v\n  binary: %#v", i, cities[i], decoded[i])
+		}
+	}
+}
+
+func TestBinary_ValueTypes(t *testing.T) {
+	cities := []CityData{{
+		City:      "Typed",
+		Lat:       -12.345678901,
+		Lng:       179.999999999,
+		Pop:       int64(1500),
+		ISO2:      "TY",
+		ISO3:      nil,
+		StateAnsi: 2.5,
+		ID:        "custom-id",
+	}}
+
+	encoded, err := EncodeBinary(cities)
+	if err != nil {
+		t.Fatalf("Expected cities to encode, got error: %v", err)
+	}
+	decoded, err := DecodeBinary(encoded)
+	if err != nil {
+		t.Fatalf("Expected cities to decode, got error: %v", err)
+	}
+
+	got := decoded[0]
+	if got.Pop != float64(1500) {
+		t.Errorf("Expected integer population to decode as float64 1500, got %v (%T)", got.Pop, got.Pop)
+	}
+	if got.StateAnsi != 2.5 || got.ISO3 != nil || got.ISO2 != "TY" || got.ID != "custom-id" {
+		t.Errorf("Unexpected decoded values: %#v", got)
+	}
+	if got.Lat != cities[0].Lat || got.Lng != cities[0].Lng {
+		t.Errorf("Expected exact coordinates %v,%v, got %v,%v", cities[0].Lat, cities[0].Lng, got.Lat, got.Lng)
+	}
+
+	if _, err := EncodeBinary([]CityData{{City: "Bad", Pop: true}}); err == nil {
+		t.Error("Expected an error for an unsupported value type")
+	}
+	if _, err := EncodeBinary([]CityData{{City: "Precise", Lat: 1.0000000001}}); err == nil {
+		t.Error("Expected an error for coordinates beyond fixed-point precision")
+	}
+}
+
+func TestDecodeBinary_Corrupt(t *testing.T) {
+	encoded, err := EncodeBinary(readJSONCities(t)[:10])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]byte{
+		"empty":       {},
+		"bad magic":   []byte("JSON[]"),
+		"bad version": append([]byte(binaryMagic), 99),
+		"truncated":   encoded[:len(encoded)/2],
+		"trailing":    append(append([]byte{}, encoded...), 0),
+
+		// A string length near 2^64 must not wrap the table size around
+		"length overflow":  binaryHeader(1, 2, math.MaxUint64, 2),
+		"lengths past end": binaryHeader(1, 2, 3, 4),
+		"count past end":   binaryHeader(1<<40, 0),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeBinary(data); err == nil {
+				t.Error("Expected an error for corrupt data")
+			}
+		})
+	}
+}
+
+// binaryHeader builds the start of a binary dataset from uvarints
+func binaryHeader(values ...uint64) []byte {
+	data := append([]byte(binaryMagic), binaryVersion)
+	for _, v := range values {
+		data = binary.AppendUvarint(data, v)
+	}
+	return append(data, "abcdefgh"...)
+}
+
+func FuzzDecodeBinary(f *testing.F) {
+	encoded, err := EncodeBinary([]CityData{
+		{City: "Chicago", Timezone: "America/Chicago", Lat: 41.8373, Lng: -87.6861, Pop: 2841952.0, ISO2: "US"},
+		{City: "Tokyo", Timezone: "Asia/Tokyo", Lat: 35.685, Lng: 139.7514, Pop: 22006299.5},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(encoded)
+	f.Add(binaryHeader(1, 2, math.MaxUint64, 2))
+	f.Add([]byte(binaryMagic))
+
+	// Corrupt input must return an error, never panic
+	f.Fuzz(func(t *testing.T, data []byte) {
+		DecodeBinary(data)
+	})
+}
+
+func BenchmarkLoadGzipJSON(b *testing.B) {
+	data, err := os.ReadFile("data/cityMap.json.gz")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := decodeDataset(data, "", &loadOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadBinary(b *testing.B) {
+	data, err := os.ReadFile("data/cityMap.bin")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Boundaries holds timezone polygons, such as those of timezone-boundary-builder,
+// for resolving coordinates by point-in-polygon instead of by nearest city
+type Boundaries struct {
+	zones []boundaryZone
+}
+
+type boundaryZone struct {
+	timezone string
+	polygons []polygon
+	bounds   []bbox // one per polygon, from its outer ring
+	extent   bbox   // all polygons
+}
+
+// Resolution is the timezone found for a point, with the evidence for it
+type Resolution struct {
+	Timezone string // the resolved timezone, "" if neither source has one
+	Source   string // "boundary" or "city"
+
+	// BoundaryTimezone is the zone whose polygon contains the point
+	BoundaryTimezone string
+
+	// City is the nearest city within 50km, if any, and CityTimezone its zone
+	City         *CityData
+	CityTimezone string
+
+	// Disagrees is set when both sources have a zone and their canonical
+	// names differ, as happens near borders
+	Disagrees bool
+}
+
+// BoundaryMismatch is a city whose timezone differs from the polygon that
+// contains it
+type BoundaryMismatch struct {
+	City             CityData
+	BoundaryTimezone string
+}
+
+// LoadBoundaries reads a GeoJSON FeatureCollection of timezone polygons from
+// a file. Each feature needs a "tzid" property and a Polygon or MultiPolygon
+// geometry, as in the timezone-boundary-builder releases.
+func LoadBoundaries(filename string) (*Boundaries, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, &LoadError{Source: filename, Err: err}
+	}
+	defer file.Close()
+
+	b, err := readBoundaries(file)
+	if err != nil {
+		return nil, &LoadError{Source: filename, Err: err}
+	}
+	return b, nil
+}
+
+// LoadBoundariesFrom reads timezone polygons from r, like LoadBoundaries
+func LoadBoundariesFrom(r io.Reader) (*Boundaries, error) {
+	b, err := readBoundaries(r)
+	if err != nil {
+		return nil, &LoadError{Source: "reader", Err: err}
+	}
+	return b, nil
+}
+
+func readBoundaries(r io.Reader) (*Boundaries, error) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties struct {
+				TZID string `json:"tzid"`
+			} `json:"properties"`
+			Geometry geoJSONGeometry `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to parse GeoJSON: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("expected a GeoJSON FeatureCollection, got %q", collection.Type)
+	}
+
+	b := &Boundaries{}
+	for i, feature := range collection.Features {
+		if feature.Properties.TZID == "" {
+			return nil, fmt.Errorf("feature %d: missing tzid property", i)
+		}
+		polygons, err := feature.Geometry.polygons()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d (%s): %w", i, feature.Properties.TZID, err)
+		}
+
+		zone := boundaryZone{timezone: feature.Properties.TZID, polygons: polygons}
+		for j, p := range polygons {
+			bounds := ringBounds(p[0])
+			zone.bounds = append(zone.bounds, bounds)
+			if j == 0 {
+				zone.extent = bounds
+			} else {
+				zone.extent = zone.extent.union(bounds)
+			}
+		}
+		b.zones = append(b.zones, zone)
+	}
+	return b, nil
+}
+
+// Lookup returns the timezone whose polygon contains the point
+func (b *Boundaries) Lookup(lat, lng float64) (string, bool) {
+	for _, zone := range b.zones {
+		if !zone.extent.contains(lng, lat) {
+			continue
+		}
+		for i, p := range zone.polygons {
+			if zone.bounds[i].contains(lng, lat) && p.contains(lng, lat) {
+				return zone.timezone, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Timezones returns the number of timezones in the boundary data
+func (b *Boundaries) Timezones() int {
+	return len(b.zones)
+}
+
+// ResolveTimezone resolves the timezone of a point from boundary polygons,
+// falling back to the nearest city
+func ResolveTimezone(b *Boundaries, lat, lng float64) Resolution {
+	return Default().ResolveTimezone(b, lat, lng)
+}
+
+// ResolveTimezone resolves the timezone of a point from boundary polygons,
+// falling back to the nearest city in the database within 50km. Both
+// answers are reported so that disagreements near borders can be logged.
+func (db *Database) ResolveTimezone(b *Boundaries, lat, lng float64) Resolution {
+	var r Resolution
+	if b != nil {
+		r.BoundaryTimezone, _ = b.Lookup(lat, lng)
+	}
+	if nearest := db.FindNearestCities(lat, lng, 50.0); len(nearest) > 0 {
+		r.City = &nearest[0]
+		r.CityTimezone = nearest[0].Timezone
+	}
+
+	switch {
+	case r.BoundaryTimezone != "":
+		r.Timezone, r.Source = r.BoundaryTimezone, "boundary"
+	case r.CityTimezone != "":
+		r.Timezone, r.Source = r.CityTimezone, "city"
+	}
+	r.Disagrees = r.BoundaryTimezone != "" && r.CityTimezone != "" &&
+		CanonicalZone(r.BoundaryTimezone) != CanonicalZone(r.CityTimezone)
+	return r
+}
+
+// CheckBoundaries lists the cities in the database whose timezone differs
+// from the boundary polygon containing them. Cities outside every polygon
+// are not reported.
+func (db *Database) CheckBoundaries(b *Boundaries) []BoundaryMismatch {
+	var mismatches []BoundaryMismatch
+	for _, c := range db.cities {
+		tz, ok := b.Lookup(c.Lat, c.Lng)
+		if ok && CanonicalZone(tz) != CanonicalZone(c.Timezone) {
+			mismatches = append(mismatches, BoundaryMismatch{City: c, BoundaryTimezone: tz})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,127 @@
+package citytimezones
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Two zones split at lng -87; the eastern one has a hole that belongs to a
+// third zone, and the third zone also has a separate island
+const testBoundaries = `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "properties": {"tzid": "America/Chicago"},
+     "geometry": {"type": "Polygon", "coordinates": [[[-90, 40], [-87, 40], [-87, 43], [-90, 43], [-90, 40]]]}},
+    {"type": "Feature", "properties": {"tzid": "America/New_York"},
+     "geometry": {"type": "Polygon", "coordinates": [
+       [[-87, 40], [-84, 40], [-84, 43], [-87, 43], [-87, 40]],
+       [[-86, 41], [-85, 41], [-85, 42], [-86, 42], [-86, 41]]]}},
+    {"type": "Feature", "properties": {"tzid": "America/Indiana/Knox"},
+     "geometry": {"type": "MultiPolygon", "coordinates": [
+       [[[-86, 41], [-85, 41], [-85, 42], [-86, 42], [-86, 41]]],
+       [[[-80, 30], [-79, 30], [-79, 31], [-80, 31], [-80, 30]]]]}}
+  ]
+}`
+
+func TestBoundaries_Lookup(t *testing.T) {
+	b, err := LoadBoundariesFrom(strings.NewReader(testBoundaries))
+	if err != nil {
+		t.Fatalf("Expected boundaries to load, got error: %v", err)
+	}
+	if b.Timezones() != 3 {
+		t.Errorf("Expected 3 timezones, got %d", b.Timezones())
+	}
+
+	tests := []struct {
+		lat, lng float64
+		want     string
+	}{
+		{41.5, -88.5, "America/Chicago"},
+		{40.5, -84.5, "America/New_York"},
+		{41.5, -85.5, "America/Indiana/Knox"}, // in the hole
+		{30.5, -79.5, "America/Indiana/Knox"}, // second polygon
+		{10, 10, ""},
+	}
+	for _, tt := range tests {
+		got, ok := b.Lookup(tt.lat, tt.lng)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Lookup(%v, %v): expected %q, got %q (%v)", tt.lat, tt.lng, tt.want, got, ok)
+		}
+	}
+}
+
+func TestLoadBoundaries_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not GeoJSON":    `{`,
+		"no collection":  `{"type": "Feature"}`,
+		"missing tzid":   `{"type": "FeatureCollection", "features": [{"properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0,0],[1,0],[1,1],[0,0]]]}}]}`,
+		"point geometry": `{"type": "FeatureCollection", "features": [{"properties": {"tzid": "UTC"}, "geometry": {"type": "Point", "coordinates": [0, 0]}}]}`,
+	}
+	for name, data := range tests {
+		if _, err := LoadBoundariesFrom(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	_, err := LoadBoundaries(filepath.Join(t.TempDir(), "missing.geojson"))
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected *LoadError wrapping os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestResolveTimezone(t *testing.T) {
+	b, err := LoadBoundariesFrom(strings.NewReader(testBoundaries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := NewDatabase([]CityData{
+		{City: "West", Lat: 41.5, Lng: -87.1, Timezone: "America/Chicago"},
+		{City: "Faraway", Lat: 10, Lng: 10, Timezone: "Africa/Lagos"},
+	})
+
+	// East of the line, but the nearest city is on the Chicago side
+	r := db.ResolveTimezone(b, 41.5, -86.9)
+	if r.Timezone != "America/New_York" || r.Source != "boundary" {
+		t.Errorf("Expected America/New_York from boundary, got %s from %s", r.Timezone, r.Source)
+	}
+	if !r.Disagrees || r.CityTimezone != "America/Chicago" || r.City == nil || r.City.City != "West" {
+		t.Errorf("Expected disagreement with West (America/Chicago), got %+v", r)
+	}
+
+	// Outside every polygon the nearest city is used
+	r = db.ResolveTimezone(b, 10.1, 10.1)
+	if r.Timezone != "Africa/Lagos" || r.Source != "city" || r.Disagrees {
+		t.Errorf("Expected Africa/Lagos from city, got %+v", r)
+	}
+
+	// No boundary data
+	r = db.ResolveTimezone(nil, 41.5, -86.9)
+	if r.Timezone != "America/Chicago" || r.Source != "city" {
+		t.Errorf("Expected city fallback without boundaries, got %+v", r)
+	}
+}
+
+func TestCheckBoundaries(t *testing.T) {
+	b, err := LoadBoundariesFrom(strings.NewReader(testBoundaries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := NewDatabase([]CityData{
+		{City: "Right", Lat: 41.5, Lng: -88.5, Timezone: "America/Chicago"},
+		{City: "Wrong", Lat: 40.5, Lng: -84.5, Timezone: "America/Chicago"},
+		{City: "Alias", Lat: 40.5, Lng: -84.6, Timezone: "US/Eastern"},
+		{City: "Outside", Lat: 10, Lng: 10, Timezone: "Africa/Lagos"},
+	})
+
+	mismatches := db.CheckBoundaries(b)
+	if len(mismatches) != 1 {
+		t.Fatalf("Expected 1 mismatch, got %d", len(mismatches))
+	}
+	if mismatches[0].City.City != "Wrong" || mismatches[0].BoundaryTimezone != "America/New_York" {
+		t.Errorf("Expected Wrong in America/New_York, got %+v", mismatches[0])
+	}
+}
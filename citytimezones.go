@@ -1,15 +1,13 @@
 package citytimezones
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"sort"
-	"strconv"
 	"strings"
-	
-	"github.com/google/open-location-code/go"
+	"sync/atomic"
 )
 
 // CityData represents a city with timezone and location information
@@ -27,9 +25,43 @@ type CityData struct {
 	StateAnsi   interface{} `json:"state_ansi,omitempty"` // Can be string or null
 	ExactCity   interface{} `json:"exactCity,omitempty"`  // Can be string or null
 	ExactProvince interface{} `json:"exactProvince,omitempty"` // Can be string or null
+	ID          string      `json:"id,omitempty"` // Stable identifier, see CityID
+}
+
+// Database is a queryable set of cities. The package-level lookup functions
+// operate on the default database loaded at initialization; additional
+// databases can be built from custom datasets with NewDatabase or LoadCSV.
+type Database struct {
+	cities []CityData
+	byID   map[string]int
+	byLat  []int // positions sorted by latitude, see latIndex
+
+	// overlayReport is set when the database was built by ApplyOverlay
+	overlayReport *OverlayReport
+}
+
+// NewDatabase creates a database over a copy of the given cities, assigning
+// each city without an ID its stable identifier (see CityID)
+func NewDatabase(cities []CityData) *Database {
+	db := &Database{
+		cities: make([]CityData, len(cities)),
+		byID:   make(map[string]int, len(cities)),
+	}
+	copy(db.cities, cities)
+	assignIDs(db.cities, db.byID)
+	db.byLat = latIndex(db.cities)
+	return db
 }
 
-var cityMapping []CityData
+// defaultDB holds the database used by the package-level functions. Reload
+// swaps it atomically, so readers always see a complete dataset.
+var defaultDB atomic.Pointer[Database]
+
+// Default returns the current default database. Holding on to it gives a
+// consistent view across several queries, even if Reload runs meanwhile.
+func Default() *Database {
+	return defaultDB.Load()
+}
 
 // Initialize loads the city data (embedded first, then external file fallback)
 func init() {
@@ -39,26 +71,93 @@ func init() {
 	}
 }
 
-// loadCityData tries embedded data first, then falls back to external file
+// loadCityData loads the default dataset and installs it as the first generation
 func loadCityData() error {
+	db, err := buildDefaultDatabase("")
+	if err != nil {
+		return err
+	}
+	
+	storeDefault(db)
+	return nil
+}
+
+// buildDefaultDatabase loads the data file at source, or the default dataset
+// (see loadDefaultDatabase) if source is empty.
+// If CITYTZ_OVERLAY names an overlay file, it is applied to the loaded data,
+// which keeps its report, and if CITYTZ_CANONICAL_ZONES is true, its
+// timezones are canonicalized.
+func buildDefaultDatabase(source string) (*Database, error) {
+	var db *Database
+	var err error
+	if source != "" {
+		db, err = LoadFile(source)
+	} else {
+		db, err = loadDefaultDatabase()
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	if path := os.Getenv(overlayEnvVar); path != "" {
+		overlay, err := LoadOverlayFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if db, _, err = db.ApplyOverlay(overlay); err != nil {
+			return nil, fmt.Errorf("failed to apply overlay %s: %w", path, err)
+		}
+	}
+	
+	canonical, err := canonicalZonesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if canonical {
+		cities := append([]CityData(nil), db.Cities()...)
+		canonicalizeZones(cities)
+		report := db.overlayReport
+		db = NewDatabase(cities)
+		db.overlayReport = report
+	}
+	
+	return db, nil
+}
+
+// loadDefaultDatabase loads the file named by CITYTZ_DATA if set. Otherwise
+// it tries embedded data first, then falls back to external file.
+func loadDefaultDatabase() (*Database, error) {
+	// An explicit data file replaces the embedded data, without fallback
+	if path := os.Getenv(dataEnvVar); path != "" {
+		db, err := LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dataEnvVar, err)
+		}
+		return db, nil
+	}
+	
 	// Try embedded data first
 	cities, err := loadEmbeddedCityData()
 	if err == nil {
-		cityMapping = cities
-		return nil
+		return NewDatabase(cities), nil
 	}
+	embeddedErr := &LoadError{Source: "embedded data", Err: err}
 	
-	// Fallback to external JSON file
-	data, err := os.ReadFile("data/cityMap.json")
+	// Fallback to external data file
+	db, err := LoadFile(externalDataPath)
 	if err != nil {
-		return fmt.Errorf("failed to load both embedded and external data: %w", err)
+		return nil, fmt.Errorf("failed to load both embedded and external data: %v; %w", embeddedErr, err)
 	}
-	
-	return json.Unmarshal(data, &cityMapping)
+	return db, nil
 }
 
 // LookupViaCity finds cities by exact name match (case-insensitive)
 func LookupViaCity(city string) []CityData {
+	return Default().LookupViaCity(city)
+}
+
+// LookupViaCity finds cities in the database by exact name match (case-insensitive)
+func (db *Database) LookupViaCity(city string) []CityData {
 	var results []CityData
 	cityTrimmed := strings.TrimSpace(city)
 	if cityTrimmed == "" {
@@ -66,7 +165,7 @@ func LookupViaCity(city string) []CityData {
 	}
 	cityLower := strings.ToLower(cityTrimmed)
 	
-	for _, c := range cityMapping {
+	for _, c := range db.cities {
 		if strings.ToLower(c.City) == cityLower {
 			results = append(results, c)
 		}
@@ -95,13 +194,18 @@ func findPartialMatch(searchFields []string, searchString string) bool {
 
 // FindFromCityStateProvince finds cities by partial matching across city/state/province/country
 func FindFromCityStateProvince(searchString string) []CityData {
+	return Default().FindFromCityStateProvince(searchString)
+}
+
+// FindFromCityStateProvince finds cities in the database by partial matching across city/state/province/country
+func (db *Database) FindFromCityStateProvince(searchString string) []CityData {
 	if searchString == "" {
 		return []CityData{}
 	}
 	
 	var results []CityData
 	
-	for _, c := range cityMapping {
+	for _, c := range db.cities {
 		searchFields := []string{c.City}
 		
 		if c.StateAnsi != nil {
@@ -122,6 +226,11 @@ func FindFromCityStateProvince(searchString string) []CityData {
 
 // FindFromIsoCode finds cities by ISO2 or ISO3 country code
 func FindFromIsoCode(isoCode string) []CityData {
+	return Default().FindFromIsoCode(isoCode)
+}
+
+// FindFromIsoCode finds cities in the database by ISO2 or ISO3 country code
+func (db *Database) FindFromIsoCode(isoCode string) []CityData {
 	isoTrimmed := strings.TrimSpace(isoCode)
 	if isoTrimmed == "" {
 		return []CityData{}
@@ -130,7 +239,7 @@ func FindFromIsoCode(isoCode string) []CityData {
 	var results []CityData
 	isoLower := strings.ToLower(isoTrimmed)
 	
-	for _, c := range cityMapping {
+	for _, c := range db.cities {
 		iso2Match := false
 		iso3Match := false
 		
@@ -156,7 +265,12 @@ func FindFromIsoCode(isoCode string) []CityData {
 
 // GetCityMapping returns the complete city dataset
 func GetCityMapping() []CityData {
-	return cityMapping
+	return Default().Cities()
+}
+
+// Cities returns every city in the database
+func (db *Database) Cities() []CityData {
+	return db.cities
 }
 
 // CityDistance represents a city with its distance from a reference point
@@ -191,9 +305,14 @@ func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
 
 // FindNearestCities finds all cities within a specified radius (in kilometers) of the given coordinates
 func FindNearestCities(lat, lng, radiusKm float64) []CityData {
+	return Default().FindNearestCities(lat, lng, radiusKm)
+}
+
+// FindNearestCities finds all cities in the database within a specified radius (in kilometers) of the given coordinates
+func (db *Database) FindNearestCities(lat, lng, radiusKm float64) []CityData {
 	var results []CityDistance
 
-	for _, city := range cityMapping {
+	for _, city := range db.cities {
 		distance := haversineDistance(lat, lng, city.Lat, city.Lng)
 		if distance <= radiusKm {
 			results = append(results, CityDistance{
@@ -217,71 +336,64 @@ func FindNearestCities(lat, lng, radiusKm float64) []CityData {
 	return cities
 }
 
-// parseCoordinates attempts to parse coordinates from various input types
-func parseCoordinates(coords interface{}) (lat, lng float64, err error) {
-	switch v := coords.(type) {
-	case string:
-		// Parse "lat,lng" format
-		parts := strings.Split(v, ",")
-		if len(parts) != 2 {
-			return 0, 0, fmt.Errorf("invalid coordinate string format, expected 'lat,lng'")
-		}
-		
-		lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid latitude: %v", err)
-		}
-		
-		lng, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-		if err != nil {
-			return 0, 0, fmt.Errorf("invalid longitude: %v", err)
-		}
-		
-		return lat, lng, nil
-		
-	case [2]float64:
-		return v[0], v[1], nil
-		
-	case []float64:
-		if len(v) != 2 {
-			return 0, 0, fmt.Errorf("coordinate slice must have exactly 2 elements")
-		}
-		return v[0], v[1], nil
-		
-	default:
-		return 0, 0, fmt.Errorf("unsupported coordinate type: %T", coords)
-	}
-}
-
 // FindFromCoordinates finds the nearest cities to the given coordinates (flexible input)
-// Supports string "lat,lng", [2]float64{lat, lng}, or []float64{lat, lng}
+// Supports string "lat,lng", [2]float64{lat, lng}, or []float64{lat, lng}, and
+// strings in DMS, UTM, MGRS or Maidenhead format (see ParseDMS, ParseUTM,
+// ParseMGRS and ParseMaidenhead). Other types can be added with RegisterParser.
 func FindFromCoordinates(coords interface{}) []CityData {
+	return Default().FindFromCoordinates(coords)
+}
+
+// FindFromCoordinates finds the nearest cities in the database to the given coordinates (flexible input).
+// Unlike FindFromCoordinatesE, it does not check that parsed coordinates are on the globe.
+func (db *Database) FindFromCoordinates(coords interface{}) []CityData {
 	lat, lng, err := parseCoordinates(coords)
 	if err != nil {
 		return []CityData{}
 	}
 	
 	// Default radius of 50km for coordinate searches
-	return FindNearestCities(lat, lng, 50.0)
+	return db.FindNearestCities(lat, lng, 50.0)
 }
 
-// FindFromPlusCode finds cities near the location specified by a Plus Code (Open Location Code)
-func FindFromPlusCode(plusCode string) []CityData {
-	plusCodeTrimmed := strings.TrimSpace(plusCode)
-	if plusCodeTrimmed == "" {
-		return []CityData{}
+// FindFromCoordinatesE is like FindFromCoordinates but returns the reason
+// the coordinates could not be parsed, and rejects coordinates off the globe
+func FindFromCoordinatesE(coords interface{}) ([]CityData, error) {
+	return Default().FindFromCoordinatesE(coords)
+}
+
+// FindFromCoordinatesE finds the nearest cities in the database to the given
+// coordinates. Errors wrap ErrInvalidCoordinates, also for a latitude or
+// longitude out of range, and ErrUnsupportedCoordinates for types no parser
+// handles.
+func (db *Database) FindFromCoordinatesE(coords interface{}) ([]CityData, error) {
+	lat, lng, err := parseCoordinates(coords)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCoordinates) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCoordinates, err)
+	}
+	if err := validateLatLng(lat, lng); err != nil {
+		return nil, err
 	}
 	
-	// Decode the plus code to get coordinates
-	area, err := olc.Decode(plusCodeTrimmed)
+	// Default radius of 50km for coordinate searches
+	return db.FindNearestCities(lat, lng, 50.0), nil
+}
+
+// FindFromPlusCode finds cities near the location specified by a Plus Code (Open Location Code)
+// Short codes followed by a locality, as Google Maps shows them ("P27M+XF Chicago"), are
+// recovered with FindFromShortPlusCode.
+func FindFromPlusCode(plusCode string) []CityData {
+	return Default().FindFromPlusCode(plusCode)
+}
+
+// FindFromPlusCode finds cities in the database near the location specified by a Plus Code (Open Location Code)
+func (db *Database) FindFromPlusCode(plusCode string) []CityData {
+	cities, err := db.FindFromPlusCodeE(plusCode)
 	if err != nil {
 		return []CityData{}
 	}
-	
-	// Use the center of the plus code area
-	centerLat := area.LatLo + (area.LatHi-area.LatLo)/2
-	centerLng := area.LngLo + (area.LngHi-area.LngLo)/2
-	
-	// Default radius of 50km for plus code searches
-	return FindNearestCities(centerLat, centerLng, 50.0)
+	return cities
 }
\ No newline at end of file
@@ -1,3 +1,5 @@
+//go:build !citytz_small && !citytz_large
+
 package citytimezones
 
 import (
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+const defaultOutputPath = "data/large/cityMap.json.gz"
+
+// GeoNames dump columns, see https://download.geonames.org/export/dump/readme.txt
+const (
+	colName        = 1
+	colASCIIName   = 2
+	colLatitude    = 4
+	colLongitude   = 5
+	colCountryCode = 8
+	colAdmin1Code  = 10
+	colPopulation  = 14
+	colTimezone    = 17
+	geonamesCols   = 19
+)
+
+type country struct {
+	name string
+	iso3 string
+}
+
+func main() {
+	input := flag.String("input", "", "GeoNames cities dump, e.g. cities1000.txt or cities15000.txt (required)")
+	admin1Path := flag.String("admin1", "", "GeoNames admin1CodesASCII.txt, maps admin1 codes to province names")
+	countriesPath := flag.String("countries", "", "GeoNames countryInfo.txt, maps ISO2 codes to country names and ISO3 codes")
+	output := flag.String("output", defaultOutputPath, "gzipped JSON output, embedded by the citytz_large build tag")
+	minPop := flag.Int64("min-population", 0, "skip places with a smaller population")
+	flag.Parse()
+
+	if *input == "" {
+		fmt.Println("ERROR: -input is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	admin1 := map[string]string{}
+	if *admin1Path != "" {
+		var err error
+		if admin1, err = readAdmin1Codes(*admin1Path); err != nil {
+			fmt.Printf("ERROR: Failed to read admin1 codes: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d admin1 codes from %s\n", len(admin1), *admin1Path)
+	}
+
+	countries := map[string]country{}
+	if *countriesPath != "" {
+		var err error
+		if countries, err = readCountryInfo(*countriesPath); err != nil {
+			fmt.Printf("ERROR: Failed to read country info: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d countries from %s\n", len(countries), *countriesPath)
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to open input: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	cities, skipped, err := readCities(file, admin1, countries, *minPop)
+	if err != nil {
+		fmt.Printf("ERROR: %s: %v\n", *input, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported %d cities (%d skipped)\n", len(cities), skipped)
+
+	if err := writeGzipJSON(*output, cities); err != nil {
+		fmt.Printf("ERROR: Failed to write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s\n", *output)
+	fmt.Println("Build with -tags citytz_large to embed it.")
+}
+
+// readCities converts the rows of a GeoNames cities dump. Rows without a
+// resolvable timezone or below the population threshold are skipped.
+func readCities(r io.Reader, admin1 map[string]string, countries map[string]country, minPop int64) ([]citytimezones.CityData, int, error) {
+	var cities []citytimezones.CityData
+	skipped := 0
+	zones := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // alternatenames can be long
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < geonamesCols {
+			return nil, 0, fmt.Errorf("line %d: expected %d columns, got %d", line, geonamesCols, len(fields))
+		}
+
+		lat, err := strconv.ParseFloat(fields[colLatitude], 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid latitude %q", line, fields[colLatitude])
+		}
+		lng, err := strconv.ParseFloat(fields[colLongitude], 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: invalid longitude %q", line, fields[colLongitude])
+		}
+		pop, _ := strconv.ParseInt(fields[colPopulation], 10, 64)
+		if pop < minPop {
+			skipped++
+			continue
+		}
+
+		tz := fields[colTimezone]
+		if _, known := zones[tz]; !known {
+			_, err := time.LoadLocation(tz)
+			zones[tz] = tz != "" && err == nil
+		}
+		if !zones[tz] {
+			skipped++
+			continue
+		}
+
+		iso2 := fields[colCountryCode]
+		admin1Code := fields[colAdmin1Code]
+		info, ok := countries[iso2]
+		if !ok {
+			info = country{name: iso2}
+		}
+
+		city := citytimezones.CityData{
+			City:      fields[colName],
+			CityAscii: fields[colASCIIName],
+			Lat:       lat,
+			Lng:       lng,
+			Pop:       pop,
+			Country:   info.name,
+			ISO2:      iso2,
+			ISO3:      info.iso3,
+			Province:  admin1[iso2+"."+admin1Code],
+			Timezone:  tz,
+		}
+		// GeoNames uses postal abbreviations as US admin1 codes
+		if iso2 == "US" && admin1Code != "" {
+			city.StateAnsi = admin1Code
+		}
+		cities = append(cities, city)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return cities, skipped, nil
+}
+
+// readAdmin1Codes reads admin1CodesASCII.txt ("US.IL<TAB>Illinois<TAB>...")
+func readAdmin1Codes(path string) (map[string]string, error) {
+	codes := map[string]string{}
+	err := readTabFile(path, func(fields []string) {
+		if len(fields) >= 2 {
+			codes[fields[0]] = fields[1]
+		}
+	})
+	return codes, err
+}
+
+// readCountryInfo reads countryInfo.txt, skipping its "#" comment header
+func readCountryInfo(path string) (map[string]country, error) {
+	countries := map[string]country{}
+	err := readTabFile(path, func(fields []string) {
+		if len(fields) >= 5 && !strings.HasPrefix(fields[0], "#") {
+			countries[fields[0]] = country{name: fields[4], iso3: fields[1]}
+		}
+	})
+	return countries, err
+}
+
+func readTabFile(path string, fn func(fields []string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fn(strings.Split(scanner.Text(), "\t"))
+	}
+	return scanner.Err()
+}
+
+// writeGzipJSON writes the cities as gzipped JSON with a fixed header, so
+// identical input produces an identical file
+func writeGzipJSON(path string, cities []citytimezones.CityData) error {
+	var buf bytes.Buffer
+	gzWriter, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(gzWriter)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(cities); err != nil {
+		return err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+func readFixture(t *testing.T, minPop int64) ([]citytimezones.CityData, int) {
+	t.Helper()
+	admin1, err := readAdmin1Codes("testdata/admin1CodesASCII.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	countries, err := readCountryInfo("testdata/countryInfo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open("testdata/cities.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cities, skipped, err := readCities(file, admin1, countries, minPop)
+	if err != nil {
+		t.Fatalf("Expected fixture to import, got error: %v", err)
+	}
+	return cities, skipped
+}
+
+func TestReadAdmin1Codes(t *testing.T) {
+	codes, err := readAdmin1Codes("testdata/admin1CodesASCII.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 4 {
+		t.Errorf("Expected 4 admin1 codes, got %d", len(codes))
+	}
+	if codes["US.IL"] != "Illinois" || codes["BR.27"] != "São Paulo" {
+		t.Errorf("Expected admin1 names by country.code, got %v", codes)
+	}
+
+	if _, err := readAdmin1Codes("testdata/missing.txt"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestReadCountryInfo(t *testing.T) {
+	countries, err := readCountryInfo("testdata/countryInfo.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(countries) != 3 {
+		t.Errorf("Expected 3 countries without the comment header, got %d", len(countries))
+	}
+	if got := countries["US"]; got.name != "United States" || got.iso3 != "USA" {
+		t.Errorf("Expected United States/USA, got %+v", got)
+	}
+}
+
+func TestReadCities(t *testing.T) {
+	cities, skipped := readFixture(t, 0)
+
+	// Nowhere has an unknown timezone
+	if len(cities) != 5 || skipped != 1 {
+		t.Fatalf("Expected 5 cities and 1 skipped, got %d and %d", len(cities), skipped)
+	}
+
+	chicago := cities[0]
+	if chicago.City != "Chicago" || chicago.Province != "Illinois" || chicago.Country != "United States" ||
+		chicago.ISO2 != "US" || chicago.ISO3 != "USA" || chicago.StateAnsi != "IL" ||
+		chicago.Timezone != "America/Chicago" || chicago.Pop != int64(2720546) {
+		t.Errorf("Unexpected Chicago record: %+v", chicago)
+	}
+	if chicago.Lat != 41.85003 || chicago.Lng != -87.65005 {
+		t.Errorf("Expected Chicago at 41.85003,-87.65005, got %v,%v", chicago.Lat, chicago.Lng)
+	}
+
+	// Only US admin1 codes are postal abbreviations
+	if london := cities[1]; london.StateAnsi != nil || london.Province != "England" {
+		t.Errorf("Expected England without StateAnsi, got %+v", london)
+	}
+
+	// Countries missing from countryInfo keep their ISO2 code as the name
+	saoPaulo := cities[3]
+	if saoPaulo.City != "São Paulo" || saoPaulo.CityAscii != "Sao Paulo" || saoPaulo.Country != "BR" || saoPaulo.ISO3 != "" {
+		t.Errorf("Unexpected São Paulo record: %+v", saoPaulo)
+	}
+}
+
+func TestReadCities_MinPopulation(t *testing.T) {
+	cities, skipped := readFixture(t, 1000)
+	if len(cities) != 4 || skipped != 2 {
+		t.Errorf("Expected 4 cities and 2 skipped, got %d and %d", len(cities), skipped)
+	}
+	for _, c := range cities {
+		if c.City == "Smallville" {
+			t.Error("Expected Smallville to be dropped by the population filter")
+		}
+	}
+}
+
+func TestReadCities_Malformed(t *testing.T) {
+	tests := map[string]string{
+		"short row":     "1\tChicago\tChicago\n",
+		"bad latitude":  "1\tX\tX\t\tnorth\t0\tP\tPPL\tUS\t\tIL\t\t\t\t1\t\t0\tAmerica/Chicago\t2024-01-01\n",
+		"bad longitude": "1\tX\tX\t\t0\twest\tP\tPPL\tUS\t\tIL\t\t\t\t1\t\t0\tAmerica/Chicago\t2024-01-01\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := readCities(strings.NewReader(input), nil, nil, 0)
+			if err == nil || !strings.Contains(err.Error(), "line 1") {
+				t.Errorf("Expected an error naming line 1, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteGzipJSON(t *testing.T) {
+	cities, _ := readFixture(t, 0)
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json.gz")
+	second := filepath.Join(dir, "second.json.gz")
+	if err := writeGzipJSON(first, cities); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeGzipJSON(second, cities); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if !bytes.Equal(a, b) {
+		t.Error("Expected identical output for identical input")
+	}
+
+	// The output loads as a dataset, as the citytz_large build embeds it
+	db, err := citytimezones.LoadFile(first)
+	if err != nil {
+		t.Fatalf("Expected output to load, got error: %v", err)
+	}
+	if got := db.LookupViaCity("Tokyo"); len(got) != 1 || got[0].Province != "Tokyo" || got[0].ISO3 != "JPN" {
+		t.Errorf("Expected Tokyo with province and ISO3, got %+v", got)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+// diffCity identifies a city in a semantic diff
+type diffCity struct {
+	ID       string `json:"id"`
+	City     string `json:"city"`
+	Province string `json:"province,omitempty"`
+	Country  string `json:"country"`
+	Timezone string `json:"timezone"`
+}
+
+// fieldChange is one changed field of a city present in both datasets
+type fieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+type cityChange struct {
+	diffCity
+	Changes []fieldChange `json:"changes"`
+}
+
+// cityDiff is the semantic difference between the local and upstream data
+type cityDiff struct {
+	Added   []diffCity   `json:"added"`
+	Removed []diffCity   `json:"removed"`
+	Changed []cityChange `json:"changed"`
+}
+
+func newDiffCity(c citytimezones.CityData) diffCity {
+	return diffCity{ID: c.ID, City: c.City, Province: c.Province, Country: c.Country, Timezone: c.Timezone}
+}
+
+// diffCities matches cities by stable ID first. Cities whose ID changed
+// because they moved are then matched by name, province and country when
+// that is unique on both sides, so they show up as changed coordinates
+// rather than as a removal plus an addition.
+func diffCities(oldDB, newDB *citytimezones.Database) *cityDiff {
+	diff := &cityDiff{Added: []diffCity{}, Removed: []diffCity{}, Changed: []cityChange{}}
+
+	var unmatchedOld, unmatchedNew []citytimezones.CityData
+	for _, oldCity := range oldDB.Cities() {
+		newCity, ok := newDB.GetByID(oldCity.ID)
+		if !ok {
+			unmatchedOld = append(unmatchedOld, oldCity)
+			continue
+		}
+		if changes := compareCities(oldCity, newCity); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, cityChange{diffCity: newDiffCity(newCity), Changes: changes})
+		}
+	}
+	for _, newCity := range newDB.Cities() {
+		if _, ok := oldDB.GetByID(newCity.ID); !ok {
+			unmatchedNew = append(unmatchedNew, newCity)
+		}
+	}
+
+	oldByKey := groupByKey(unmatchedOld)
+	newByKey := groupByKey(unmatchedNew)
+	for _, oldCity := range unmatchedOld {
+		key := citytimezones.KeyOf(oldCity)
+		if len(oldByKey[key]) == 1 && len(newByKey[key]) == 1 {
+			newCity := newByKey[key][0]
+			diff.Changed = append(diff.Changed, cityChange{
+				diffCity: newDiffCity(newCity),
+				Changes:  compareCities(oldCity, newCity),
+			})
+			continue
+		}
+		diff.Removed = append(diff.Removed, newDiffCity(oldCity))
+	}
+	for _, newCity := range unmatchedNew {
+		key := citytimezones.KeyOf(newCity)
+		if len(oldByKey[key]) == 1 && len(newByKey[key]) == 1 {
+			continue
+		}
+		diff.Added = append(diff.Added, newDiffCity(newCity))
+	}
+
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].ID < diff.Changed[j].ID })
+	return diff
+}
+
+func groupByKey(cities []citytimezones.CityData) map[citytimezones.CityKey][]citytimezones.CityData {
+	groups := map[citytimezones.CityKey][]citytimezones.CityData{}
+	for _, c := range cities {
+		key := citytimezones.KeyOf(c)
+		groups[key] = append(groups[key], c)
+	}
+	return groups
+}
+
+// compareCities lists changes to the timezone, coordinates and population
+func compareCities(oldCity, newCity citytimezones.CityData) []fieldChange {
+	var changes []fieldChange
+	if oldCity.Timezone != newCity.Timezone {
+		changes = append(changes, fieldChange{"timezone", oldCity.Timezone, newCity.Timezone})
+	}
+	if oldCity.Lat != newCity.Lat || oldCity.Lng != newCity.Lng {
+		changes = append(changes, fieldChange{"coordinates",
+			[2]float64{oldCity.Lat, oldCity.Lng}, [2]float64{newCity.Lat, newCity.Lng}})
+	}
+	if fmt.Sprint(oldCity.Pop) != fmt.Sprint(newCity.Pop) {
+		changes = append(changes, fieldChange{"pop", oldCity.Pop, newCity.Pop})
+	}
+	return changes
+}
+
+// writeText renders the diff for people
+func (d *cityDiff) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Semantic diff: %d added, %d removed, %d changed\n", len(d.Added), len(d.Removed), len(d.Changed))
+	for _, c := range d.Added {
+		fmt.Fprintf(w, "  + %s (%s) [%s]\n", c.key(), c.Timezone, c.ID)
+	}
+	for _, c := range d.Removed {
+		fmt.Fprintf(w, "  - %s (%s) [%s]\n", c.key(), c.Timezone, c.ID)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "  ~ %s [%s]\n", c.key(), c.ID)
+		for _, change := range c.Changes {
+			fmt.Fprintf(w, "      %s: %v -> %v\n", change.Field, change.Old, change.New)
+		}
+	}
+}
+
+// writeJSON renders the diff for tools
+func (d *cityDiff) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+func (c diffCity) key() string {
+	return citytimezones.CityKey{City: c.City, Province: c.Province, Country: c.Country}.String()
+}
+
+// reportDiff computes the semantic diff between the local and new data and
+// writes it to w in the given format ("text" or "json")
+func reportDiff(oldData, newData []byte, format string, w io.Writer) error {
+	oldDB, err := decodeDatabase(oldData)
+	if err != nil {
+		return fmt.Errorf("local data: %w", err)
+	}
+	newDB, err := decodeDatabase(newData)
+	if err != nil {
+		return fmt.Errorf("upstream data: %w", err)
+	}
+
+	diff := diffCities(oldDB, newDB)
+	switch format {
+	case "text":
+		diff.writeText(w)
+		return nil
+	case "json":
+		return diff.writeJSON(w)
+	default:
+		return fmt.Errorf("unknown diff format %q (want text or json)", format)
+	}
+}
+
+// writeDiff writes the semantic diff to the output file, or stdout if empty
+func writeDiff(oldData, newData []byte, format, output string) error {
+	if output == "" {
+		return reportDiff(oldData, newData, format, os.Stdout)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := reportDiff(oldData, newData, format, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote semantic diff to %s\n", output)
+	return nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+func TestCompareCities(t *testing.T) {
+	base := testCity("Paris", "France", "FR", "FRA", "Europe/Paris", 48.86, 2.35)
+
+	tests := []struct {
+		name   string
+		mutate func(c *citytimezones.CityData)
+		fields []string
+	}{
+		{"unchanged", func(c *citytimezones.CityData) {}, nil},
+		{"timezone", func(c *citytimezones.CityData) { c.Timezone = "Europe/Brussels" }, []string{"timezone"}},
+		{"latitude", func(c *citytimezones.CityData) { c.Lat = 48.9 }, []string{"coordinates"}},
+		{"longitude", func(c *citytimezones.CityData) { c.Lng = 2.4 }, []string{"coordinates"}},
+		{"population", func(c *citytimezones.CityData) { c.Pop = float64(2000) }, []string{"pop"}},
+		{"population type only", func(c *citytimezones.CityData) { c.Pop = 1000 }, nil},
+		{"ignored field", func(c *citytimezones.CityData) { c.CityAscii = "PARIS" }, nil},
+		{"everything", func(c *citytimezones.CityData) {
+			c.Timezone, c.Lat, c.Pop = "Europe/Brussels", 50, nil
+		}, []string{"timezone", "coordinates", "pop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.mutate(&changed)
+
+			var fields []string
+			for _, change := range compareCities(base, changed) {
+				fields = append(fields, change.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Expected changed fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestCompareCities_Values(t *testing.T) {
+	oldCity := testCity("Paris", "France", "FR", "FRA", "Europe/Paris", 48.86, 2.35)
+	newCity := oldCity
+	newCity.Lat, newCity.Lng = 49.0, 2.5
+
+	changes := compareCities(oldCity, newCity)
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %v", changes)
+	}
+	if changes[0].Old != [2]float64{48.86, 2.35} || changes[0].New != [2]float64{49.0, 2.5} {
+		t.Errorf("Expected coordinates [48.86 2.35] -> [49 2.5], got %v -> %v", changes[0].Old, changes[0].New)
+	}
+}
+
+func TestDiffCities(t *testing.T) {
+	lyon := testCity("Lyon", "France", "FR", "FRA", "Europe/Paris", 45.76, 4.84)
+	movedLyon := lyon
+	movedLyon.Lat, movedLyon.Lng = 45.2, 4.1
+
+	biggerLyon := lyon
+	biggerLyon.Pop = float64(5000)
+
+	springfield := func(lat float64) citytimezones.CityData {
+		return testCity("Springfield", "United States of America", "US", "USA", "America/Chicago", lat, -89.6)
+	}
+
+	tests := []struct {
+		name    string
+		oldData []citytimezones.CityData
+		newData []citytimezones.CityData
+		added   []string
+		removed []string
+		changed map[string][]string
+	}{
+		{
+			name:    "identical",
+			oldData: cleanCities(),
+			newData: cleanCities(),
+		},
+		{
+			name:    "added",
+			oldData: cleanCities(),
+			newData: append(cleanCities(), lyon),
+			added:   []string{"Lyon"},
+		},
+		{
+			name:    "removed",
+			oldData: append(cleanCities(), lyon),
+			newData: cleanCities(),
+			removed: []string{"Lyon"},
+		},
+		{
+			name:    "moved",
+			oldData: []citytimezones.CityData{lyon},
+			newData: []citytimezones.CityData{movedLyon},
+			changed: map[string][]string{"Lyon": {"coordinates"}},
+		},
+		{
+			name:    "population changed",
+			oldData: []citytimezones.CityData{lyon},
+			newData: []citytimezones.CityData{biggerLyon},
+			changed: map[string][]string{"Lyon": {"pop"}},
+		},
+		{
+			name:    "ambiguous move",
+			oldData: []citytimezones.CityData{springfield(39.8), springfield(37.2)},
+			newData: []citytimezones.CityData{springfield(42.1), springfield(44.0)},
+			added:   []string{"Springfield", "Springfield"},
+			removed: []string{"Springfield", "Springfield"},
+		},
+	}
+
+	names := func(cities []diffCity) []string {
+		var out []string
+		for _, c := range cities {
+			out = append(out, c.City)
+		}
+		return out
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffCities(citytimezones.NewDatabase(tt.oldData), citytimezones.NewDatabase(tt.newData))
+
+			if got := names(diff.Added); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("Expected added %v, got %v", tt.added, got)
+			}
+			if got := names(diff.Removed); !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("Expected removed %v, got %v", tt.removed, got)
+			}
+			if len(diff.Changed) != len(tt.changed) {
+				t.Fatalf("Expected %d changed cities, got %v", len(tt.changed), diff.Changed)
+			}
+			for _, c := range diff.Changed {
+				var fields []string
+				for _, change := range c.Changes {
+					fields = append(fields, change.Field)
+				}
+				if !reflect.DeepEqual(fields, tt.changed[c.City]) {
+					t.Errorf("Expected %s to change %v, got %v", c.City, tt.changed[c.City], fields)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffCities_MovedUsesNewID(t *testing.T) {
+	lyon := testCity("Lyon", "France", "FR", "FRA", "Europe/Paris", 45.76, 4.84)
+	moved := lyon
+	moved.Lat = 45.2
+
+	diff := diffCities(citytimezones.NewDatabase([]citytimezones.CityData{lyon}),
+		citytimezones.NewDatabase([]citytimezones.CityData{moved}))
+	if len(diff.Changed) != 1 {
+		t.Fatalf("Expected 1 changed city, got %v", diff.Changed)
+	}
+	if want := citytimezones.CityID(moved); diff.Changed[0].ID != want {
+		t.Errorf("Expected the new ID %s, got %s", want, diff.Changed[0].ID)
+	}
+}
+
+func TestReportDiff_JSON(t *testing.T) {
+	lyon := testCity("Lyon", "France", "FR", "FRA", "Europe/Paris", 45.76, 4.84)
+	lyon.Province = "Auvergne-Rhone-Alpes"
+	newCities := append(cleanCities(), lyon)
+	newCities[0].Timezone = "Europe/Brussels"
+	newCities = append(newCities[:1], newCities[2:]...)
+
+	var buf bytes.Buffer
+	if err := reportDiff(marshalCities(t, cleanCities()), marshalCities(t, newCities), "json", &buf); err != nil {
+		t.Fatalf("Expected JSON diff, got error: %v", err)
+	}
+
+	var got map[string][]map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v\n%s", err, buf.String())
+	}
+	if len(got) != 3 {
+		t.Errorf("Expected only added, removed and changed keys, got %v", got)
+	}
+
+	added, removed, changed := got["added"], got["removed"], got["changed"]
+	if len(added) != 1 || len(removed) != 1 || len(changed) != 1 {
+		t.Fatalf("Expected 1 added, 1 removed and 1 changed, got %s", buf.String())
+	}
+
+	wantAdded := map[string]interface{}{
+		"id": citytimezones.CityID(lyon), "city": "Lyon", "province": "Auvergne-Rhone-Alpes",
+		"country": "France", "timezone": "Europe/Paris",
+	}
+	if !reflect.DeepEqual(added[0], wantAdded) {
+		t.Errorf("Expected added %v, got %v", wantAdded, added[0])
+	}
+	if _, ok := removed[0]["province"]; ok {
+		t.Errorf("Expected empty province to be omitted, got %v", removed[0])
+	}
+	if removed[0]["city"] != "Lyon" {
+		t.Errorf("Expected Lyon removed, got %v", removed[0])
+	}
+
+	wantChanges := []interface{}{
+		map[string]interface{}{"field": "timezone", "old": "Europe/Paris", "new": "Europe/Brussels"},
+	}
+	if changed[0]["city"] != "Paris" || !reflect.DeepEqual(changed[0]["changes"], wantChanges) {
+		t.Errorf("Expected Paris timezone change, got %v", changed[0])
+	}
+}
+
+func TestReportDiff_EmptyJSON(t *testing.T) {
+	data := marshalCities(t, cleanCities())
+
+	var buf bytes.Buffer
+	if err := reportDiff(data, data, "json", &buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"added": []`, `"removed": []`, `"changed": []`} {
+		if !strings.Contains(buf.String(), key) {
+			t.Errorf("Expected %s in empty diff, got %s", key, buf.String())
+		}
+	}
+}
+
+func TestReportDiff_Text(t *testing.T) {
+	newCities := cleanCities()
+	newCities[0].Pop = float64(3000)
+
+	var buf bytes.Buffer
+	if err := reportDiff(marshalCities(t, cleanCities()), marshalCities(t, newCities), "text", &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "Semantic diff: 0 added, 0 removed, 1 changed\n") {
+		t.Errorf("Expected summary line, got %q", out)
+	}
+	if !strings.Contains(out, "pop: 1000 -> 3000") {
+		t.Errorf("Expected population change, got %q", out)
+	}
+}
+
+func TestReportDiff_Errors(t *testing.T) {
+	data := marshalCities(t, cleanCities())
+
+	if err := reportDiff(data, data, "yaml", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+	if err := reportDiff([]byte("{"), data, "text", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unparsable local data, got nil")
+	}
+	if err := reportDiff(data, []byte("{"), "text", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unparsable new data, got nil")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+// vanishedIDs returns the cities of the old data whose stable IDs no longer
+// exist in the new data. References stored against those IDs will stop
+// resolving once the new data ships.
+func vanishedIDs(oldData, newData []byte) ([]citytimezones.CityData, error) {
+	oldDB, err := decodeDatabase(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("local data: %w", err)
+	}
+	newDB, err := decodeDatabase(newData)
+	if err != nil {
+		return nil, fmt.Errorf("upstream data: %w", err)
+	}
+
+	var vanished []citytimezones.CityData
+	for _, city := range oldDB.Cities() {
+		if _, ok := newDB.GetByID(city.ID); !ok {
+			vanished = append(vanished, city)
+		}
+	}
+	return vanished, nil
+}
+
+func decodeDatabase(data []byte) (*citytimezones.Database, error) {
+	var cities []citytimezones.CityData
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("failed to parse city data: %w", err)
+	}
+	return citytimezones.NewDatabase(cities), nil
+}
+
+// reportVanishedIDs prints the IDs that disappear with this update and
+// reports whether any did
+func reportVanishedIDs(oldData, newData []byte) (bool, error) {
+	vanished, err := vanishedIDs(oldData, newData)
+	if err != nil {
+		return false, err
+	}
+	if len(vanished) == 0 {
+		fmt.Println("All existing city IDs are preserved")
+		return false, nil
+	}
+
+	fmt.Printf("WARNING: %d city IDs vanish with this update:\n", len(vanished))
+	for _, city := range vanished {
+		fmt.Printf("  %s  %s\n", city.ID, citytimezones.KeyOf(city))
+	}
+	return true, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTZDataPath  = "/usr/share/zoneinfo/tzdata.zi"
+	defaultZoneTabPath = "/usr/share/zoneinfo/zone.tab"
+	localLinksPath     = "data/tzlinks.txt"
+)
+
+// Link kinds recorded in the alias table
+const (
+	linkBackward = "backward"
+	linkMerged   = "merged"
+)
+
+// runLinks regenerates the embedded zone alias table from the link lines of
+// a tzdata.zi file, the compact zic input shipped with tzdata
+func runLinks(args []string) {
+	flags := flag.NewFlagSet("links", flag.ExitOnError)
+	tzdata := flags.String("tzdata", defaultTZDataPath, "tzdata.zi file to read links from")
+	zoneTab := flags.String("zonetab", defaultZoneTabPath, "zone.tab file listing current location names")
+	output := flags.String("output", localLinksPath, "alias table to write")
+	flags.Parse(args)
+
+	version, links, err := readTZLinks(*tzdata)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+	locations, err := readZoneTab(*zoneTab)
+	if err != nil {
+		fmt.Printf("ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*output, []byte(formatLinks(version, links, locations)), 0644); err != nil {
+		fmt.Printf("ERROR: Failed to write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d zone aliases from tzdata %s to %s\n", len(links), version, *output)
+}
+
+// formatLinks renders the alias table. Aliases that zone.tab still lists as
+// locations are merged zones; the rest are backward-compatibility names.
+func formatLinks(version string, links map[string]string, locations map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Zone aliases from the link lines of tzdata %s (tzdata.zi), one\n", version)
+	b.WriteString("# \"alias canonical kind\" triple per line. The kind is one of:\n")
+	b.WriteString("#   backward  legacy or deprecated name from tzdata's backward file,\n")
+	b.WriteString("#             such as US/Central or Asia/Calcutta\n")
+	b.WriteString("#   merged    current location name, listed in zone.tab, whose zone\n")
+	b.WriteString("#             tzdata merged into one with the same clocks since 1970,\n")
+	b.WriteString("#             such as Europe/Bratislava\n")
+	b.WriteString("# Generated by: go run ./cmd/sync-data links\n")
+	aliases := make([]string, 0, len(links))
+	for alias := range links {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		kind := linkBackward
+		if locations[alias] {
+			kind = linkMerged
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", alias, links[alias], kind)
+	}
+	return b.String()
+}
+
+// readZoneTab returns the zone names listed in the third column of a
+// zone.tab file
+func readZoneTab(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	zones := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if fields := strings.Split(line, "\t"); len(fields) >= 3 {
+			zones[fields[2]] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("no zones found in %s", path)
+	}
+	return zones, nil
+}
+
+// readTZLinks reads the "L target alias" lines of a tzdata.zi file, resolving
+// links to links so that every alias maps to a zone. Files built with
+// backzone are rejected: they keep pre-1970 zones such as Africa/Asmara
+// apart while still linking their legacy spellings to the merged zone.
+func readTZLinks(path string) (string, map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	version := "unknown"
+	links := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "#" && fields[1] == "version" {
+			version = fields[2]
+		}
+		if len(fields) > 2 && fields[0] == "#" && fields[1] == "ddeps" {
+			for _, dep := range fields[2:] {
+				if dep == "backzone" {
+					return "", nil, fmt.Errorf("%s was built with backzone, which turns legacy links into zones; use a tzdata.zi built from the main data (PACKRATDATA unset)", path)
+				}
+			}
+		}
+		if len(fields) == 3 && fields[0] == "L" {
+			links[fields[2]] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(links) == 0 {
+		return "", nil, fmt.Errorf("no links found in %s", path)
+	}
+
+	for alias, target := range links {
+		for seen := 0; seen < len(links); seen++ {
+			next, ok := links[target]
+			if !ok {
+				break
+			}
+			target = next
+		}
+		links[alias] = target
+	}
+	return version, links, nil
+}
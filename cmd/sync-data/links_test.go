@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatLinks(t *testing.T) {
+	links := map[string]string{"US/Central": "America/Chicago", "Europe/Bratislava": "Europe/Prague"}
+	locations := map[string]bool{"America/Chicago": true, "Europe/Bratislava": true}
+
+	out := formatLinks("2025b", links, locations)
+	if !strings.HasPrefix(out, "# Zone aliases from the link lines of tzdata 2025b") {
+		t.Errorf("Expected header naming the tzdata version, got %q", out)
+	}
+	for _, line := range []string{"Europe/Bratislava Europe/Prague merged\n", "US/Central America/Chicago backward\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected %q in %q", line, out)
+		}
+	}
+	if strings.Index(out, "Europe/Bratislava Europe") > strings.Index(out, "US/Central America") {
+		t.Error("Expected aliases in sorted order")
+	}
+}
+
+func TestReadZoneTab(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zone.tab")
+	data := "# comment\nSK\t+4809+01707\tEurope/Bratislava\nUS\t+415100-0873900\tAmerica/Chicago\tCentral (most areas)\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	zones, err := readZoneTab(path)
+	if err != nil {
+		t.Fatalf("Expected zone.tab to parse, got error: %v", err)
+	}
+	if len(zones) != 2 || !zones["Europe/Bratislava"] || !zones["America/Chicago"] {
+		t.Errorf("Expected Bratislava and Chicago, got %v", zones)
+	}
+
+	if _, err := readZoneTab(filepath.Join(t.TempDir(), "missing.tab")); err == nil {
+		t.Error("Expected error for a missing file, got nil")
+	}
+}
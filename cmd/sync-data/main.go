@@ -3,11 +3,10 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	_ "time/tzdata" // validate zones against Go's tz database when the system has none
 )
 
 const (
@@ -17,53 +16,134 @@ const (
 )
 
 func main() {
-	fmt.Println("Syncing city data from upstream repository...")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "links":
+			runLinks(os.Args[2:])
+			return
+		case "windows-zones":
+			runWindowsZones(os.Args[2:])
+			return
+		}
+	}
 
-	// Create data directory if it doesn't exist
-	if err := os.MkdirAll("data", 0755); err != nil {
-		fmt.Printf("ERROR: Failed to create data directory: %v\n", err)
+	from := flag.String("from", "", "read cityMap.json from this local file instead of downloading it")
+	url := flag.String("url", upstreamURL, "download cityMap.json from this URL")
+	revision := flag.String("revision", "", "upstream commit or tag to record in the dataset metadata")
+	pin := flag.String("sha256", "", "require the source data to have this SHA-256 (hex)")
+	overlayPath := flag.String("overlay", defaultOverlayPath, "overlay file of local corrections to apply (skipped if missing)")
+	strictIDs := flag.Bool("strict-ids", false, "fail if any existing city ID vanishes from the new data")
+	maxNewIssues := flag.Int("max-new-issues", 0, "data-quality issues each category may gain over the local data")
+	maxRecordDrop := flag.Float64("max-record-drop", 1.0, "percentage of records the new data may lose")
+	diffFormat := flag.String("diff", "text", "semantic diff format: text or json")
+	diffOutput := flag.String("diff-output", "", "write the semantic diff to this file instead of stdout (written even with -dry-run)")
+	dryRun := flag.Bool("dry-run", false, "report changes without writing any data files (a -diff-output file is still written)")
+	flag.Parse()
+
+	if *from != "" && *url != upstreamURL {
+		fmt.Println("ERROR: -from and -url are mutually exclusive")
+		os.Exit(1)
+	}
+	if *diffFormat != "text" && *diffFormat != "json" {
+		fmt.Printf("ERROR: Unknown -diff format %q (want text or json)\n", *diffFormat)
 		os.Exit(1)
 	}
 
-	// Download latest data
-	fmt.Printf("Downloading latest cityMap.json from %s\n", upstreamURL)
-	resp, err := http.Get(upstreamURL)
+	if *from != "" {
+		fmt.Println("Syncing city data from local file...")
+	} else {
+		fmt.Println("Syncing city data from upstream repository...")
+	}
+
+	// Create data directory if it doesn't exist
+	if !*dryRun {
+		if err := os.MkdirAll("data", 0755); err != nil {
+			fmt.Printf("ERROR: Failed to create data directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Download latest data, or read it from a local copy
+	source := *url
+	if *from != "" {
+		source = *from
+	}
+	newData, err := fetchData(*from, *url)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to download data: %v\n", err)
+		fmt.Printf("ERROR: %v\n", err)
 		os.Exit(1)
 	}
-	defer resp.Body.Close()
+	sourceData := newData
+	fmt.Printf("SHA-256: %s\n", sha256Hex(sourceData))
+
+	// Check the data against the pinned digest
+	if *pin != "" {
+		if err := verifySHA256(newData, *pin); err != nil {
+			fmt.Printf("ERROR: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("SHA-256 matches the pinned digest")
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("ERROR: HTTP %d: %s\n", resp.StatusCode, resp.Status)
+	// Validate against the CityData schema
+	if _, err := decodeStrict(newData); err != nil {
+		fmt.Printf("ERROR: Downloaded file does not match the CityData schema: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Read response body
-	newData, err := io.ReadAll(resp.Body)
+	// Apply local corrections before comparing, so they survive the sync
+	newData, err = applyOverlay(newData, *overlayPath)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to read response: %v\n", err)
+		fmt.Printf("ERROR: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Validate JSON
-	var testData []interface{}
-	if err := json.Unmarshal(newData, &testData); err != nil {
-		fmt.Printf("ERROR: Downloaded file is not valid JSON: %v\n", err)
+	existingData, err := os.ReadFile(localJSONPath)
+	if err != nil {
+		existingData = nil
+	}
+
+	// Check data quality before anything is written
+	fmt.Println()
+	if err := validateUpdate(newData, existingData, *maxNewIssues, *maxRecordDrop); err != nil {
+		fmt.Printf("ERROR: %v\n", err)
 		os.Exit(1)
 	}
+	fmt.Println()
 
 	// Check if the new file is different from the current one
-	if existingData, err := os.ReadFile(localJSONPath); err == nil {
+	if existingData != nil {
 		if bytes.Equal(existingData, newData) {
 			fmt.Println("No changes detected in upstream data")
 			return
 		}
 		fmt.Println("Changes detected in upstream data")
+
+		// Check that stored city references keep resolving
+		vanished, err := reportVanishedIDs(existingData, newData)
+		if err != nil {
+			fmt.Printf("ERROR: Failed to check city IDs: %v\n", err)
+			os.Exit(1)
+		}
+		if vanished && *strictIDs {
+			fmt.Println("ERROR: City IDs changed and -strict-ids is set")
+			os.Exit(1)
+		}
+
+		// Show what actually changed
+		if err := writeDiff(existingData, newData, *diffFormat, *diffOutput); err != nil {
+			fmt.Printf("ERROR: Failed to diff data: %v\n", err)
+			os.Exit(1)
+		}
 	} else {
 		fmt.Println("No existing local data found")
 	}
 
+	if *dryRun {
+		fmt.Println("Dry run: no files written")
+		return
+	}
+
 	// Write the new JSON file
 	if err := os.WriteFile(localJSONPath, newData, 0644); err != nil {
 		fmt.Printf("ERROR: Failed to write %s: %v\n", localJSONPath, err)
@@ -72,13 +152,42 @@ func main() {
 	fmt.Printf("Updated %s\n", localJSONPath)
 
 	// Create compressed version for embedding
-	fmt.Println("Creating compressed version for embedding...")
+	fmt.Println("Creating compressed and binary versions for embedding...")
 	if err := compressFile(localJSONPath, localGZPath); err != nil {
 		fmt.Printf("ERROR: Failed to compress file: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Printf("Created %s\n", localGZPath)
 
+	// Create the binary dataset embedded by default builds
+	if err := writeBinaryDataset(newData, localBinaryPath); err != nil {
+		fmt.Printf("ERROR: Failed to create binary dataset: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s\n", localBinaryPath)
+
+	// Create the reduced dataset for citytz_small builds
+	smallCount, err := writeSmallDataset(newData, localSmallGZPath)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to create small dataset: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s (%d cities)\n", localSmallGZPath, smallCount)
+
+	// Record the data version for DatasetInfo
+	if err := writeMetadata(localMetadataPath, source, *revision, sourceData, newData); err != nil {
+		fmt.Printf("ERROR: Failed to write dataset metadata: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s\n", localMetadataPath)
+
+	// Record checksums of everything written
+	if err := writeManifest(localManifestPath, []string{localJSONPath, localGZPath, localBinaryPath, localSmallGZPath}); err != nil {
+		fmt.Printf("ERROR: Failed to write checksum manifest: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created %s\n", localManifestPath)
+
 	// Show file sizes and compression ratio
 	showFileStats()
 
@@ -94,6 +203,10 @@ func compressFile(src, dst string) error {
 		return err
 	}
 
+	return compressData(data, dst)
+}
+
+func compressData(data []byte, dst string) error {
 	// Create destination file
 	file, err := os.Create(dst)
 	if err != nil {
@@ -101,13 +214,19 @@ func compressFile(src, dst string) error {
 	}
 	defer file.Close()
 
-	// Create gzip writer
-	gzWriter := gzip.NewWriter(file)
-	defer gzWriter.Close()
+	// Create gzip writer with a fixed level and an empty header (no name,
+	// zero modification time), so identical input gives identical output
+	gzWriter, err := gzip.NewWriterLevel(file, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	gzWriter.Header = gzip.Header{OS: 255}
 
 	// Write compressed data
-	_, err = gzWriter.Write(data)
-	return err
+	if _, err := gzWriter.Write(data); err != nil {
+		return err
+	}
+	return gzWriter.Close()
 }
 
 func showFileStats() {
@@ -122,11 +241,21 @@ func showFileStats() {
 	gzInfo, err := os.Stat(localGZPath)
 	if err == nil {
 		fmt.Printf("  %s: %s\n", localGZPath, formatBytes(gzInfo.Size()))
+	}
 
-		if jsonInfo != nil {
-			ratio := 100.0 * (1.0 - float64(gzInfo.Size())/float64(jsonInfo.Size()))
-			fmt.Printf("Compression ratio: %.1f%%\n", ratio)
-		}
+	binInfo, err := os.Stat(localBinaryPath)
+	if err == nil {
+		fmt.Printf("  %s: %s\n", localBinaryPath, formatBytes(binInfo.Size()))
+	}
+
+	smallInfo, err := os.Stat(localSmallGZPath)
+	if err == nil {
+		fmt.Printf("  %s: %s\n", localSmallGZPath, formatBytes(smallInfo.Size()))
+	}
+
+	if jsonInfo != nil && gzInfo != nil {
+		ratio := 100.0 * (1.0 - float64(gzInfo.Size())/float64(jsonInfo.Size()))
+		fmt.Printf("Compression ratio: %.1f%%\n", ratio)
 	}
 }
 
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const localManifestPath = "data/SHA256SUMS"
+
+// writeManifest records the SHA-256 of each file in the format of
+// sha256sum, with names relative to the manifest, so that
+// "cd data && sha256sum -c SHA256SUMS" verifies them
+func writeManifest(manifest string, files []string) error {
+	dir := filepath.Dir(manifest)
+	var lines []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", sha256Hex(data), filepath.ToSlash(name)))
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i][66:] < lines[j][66:] })
+
+	return os.WriteFile(manifest, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+const localMetadataPath = "data/metadata.json"
+
+// syncTime returns the time to record for this sync. SOURCE_DATE_EPOCH, if
+// set, overrides the clock so that rebuilds produce identical metadata.
+func syncTime() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC().Truncate(time.Second), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// writeMetadata records where the data came from for the embedded
+// DatasetInfo. sourceData is the file as downloaded, before the overlay.
+func writeMetadata(path, source, revision string, sourceData, data []byte) error {
+	var cities []json.RawMessage
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return fmt.Errorf("failed to parse data: %w", err)
+	}
+	syncedAt, err := syncTime()
+	if err != nil {
+		return err
+	}
+
+	metadata := citytimezones.DatasetMetadata{
+		Source:   source,
+		Revision: revision,
+		SHA256:   sha256Hex(sourceData),
+		Records:  len(cities),
+		SyncedAt: syncedAt,
+	}
+	encoded, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(encoded, '\n'), 0644)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+const defaultOverlayPath = "data/overlay.json"
+
+// applyOverlay applies the local corrections in the overlay file to the
+// downloaded data and reports entries that no longer match upstream.
+// A missing overlay file leaves the data untouched.
+func applyOverlay(data []byte, path string) ([]byte, error) {
+	if path == "" {
+		return data, nil
+	}
+
+	overlay, err := citytimezones.LoadOverlayFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cities []citytimezones.CityData
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("failed to parse city data: %w", err)
+	}
+
+	// Assign IDs exactly as the library does at load time, suffixes for
+	// duplicates included, so that ID keys match the same records
+	db := citytimezones.NewDatabase(cities)
+	assigned := map[string]bool{}
+	for i, c := range db.Cities() {
+		if cities[i].ID == "" {
+			assigned[c.ID] = true
+		}
+	}
+
+	patched, report, err := overlay.Apply(db.Cities())
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply overlay %s: %w", path, err)
+	}
+
+	// Keep the upstream layout, which has no IDs
+	for i := range patched {
+		if assigned[patched[i].ID] {
+			patched[i].ID = ""
+		}
+	}
+
+	fmt.Printf("Applied overlay %s: %d added, %d removed, %d patched\n",
+		path, report.Added, report.Removed, report.Patched)
+	if len(report.Stale) > 0 {
+		fmt.Printf("WARNING: %d overlay entries no longer match upstream data:\n", len(report.Stale))
+		for _, issue := range report.Stale {
+			fmt.Printf("  %s\n", issue)
+		}
+	}
+
+	return encodeCities(patched)
+}
+
+// encodeCities renders cities in the same indented layout as the upstream file
+func encodeCities(cities []citytimezones.CityData) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cities); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+func TestApplyOverlay_SuffixedID(t *testing.T) {
+	// Two records with the same name, province, country and rounded
+	// coordinates: at load time the second gets the ID "<id>-2"
+	first := testCity("Bandar Lampung", "Indonesia", "ID", "IDN", "Asia/Jakarta", -5.43, 105.27)
+	second := first
+	second.Pop = float64(2000)
+	data := marshalCities(t, []citytimezones.CityData{first, second})
+
+	id := citytimezones.NewDatabase([]citytimezones.CityData{first, second}).Cities()[1].ID
+	if !strings.HasSuffix(id, "-2") {
+		t.Fatalf("Expected a suffixed ID for the duplicate, got %s", id)
+	}
+
+	path := filepath.Join(t.TempDir(), "overlay.json")
+	overlay := `{"patch": [{"match": {"id": "` + id + `"}, "set": {"pop": 3000}}]}`
+	if err := os.WriteFile(path, []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := applyOverlay(data, path)
+	if err != nil {
+		t.Fatalf("Expected overlay to apply, got error: %v", err)
+	}
+	var cities []citytimezones.CityData
+	if err := json.Unmarshal(out, &cities); err != nil {
+		t.Fatal(err)
+	}
+	if len(cities) != 2 || cities[0].Pop != float64(1000) || cities[1].Pop != float64(3000) {
+		t.Errorf("Expected only the second record patched, got %v", cities)
+	}
+	if strings.Contains(string(out), `"id"`) {
+		t.Errorf("Expected output without assigned IDs, got %s", out)
+	}
+
+	// The same overlay matches the same record at load time
+	db, report, err := citytimezones.NewDatabase([]citytimezones.CityData{first, second}).ApplyOverlay(mustParseOverlay(t, overlay))
+	if err != nil || len(report.Stale) != 0 || db.Cities()[1].Pop != float64(3000) {
+		t.Errorf("Expected the load-time overlay to patch the second record, got %v, %v", report, err)
+	}
+}
+
+func mustParseOverlay(t *testing.T, data string) *citytimezones.Overlay {
+	t.Helper()
+	overlay, err := citytimezones.ParseOverlay(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return overlay
+}
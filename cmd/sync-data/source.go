@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// fetchData reads the upstream data from a local file if from is set,
+// otherwise downloads it from url
+func fetchData(from, url string) ([]byte, error) {
+	if from != "" {
+		fmt.Printf("Reading cityMap.json from %s\n", from)
+		data, err := os.ReadFile(from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", from, err)
+		}
+		return data, nil
+	}
+
+	fmt.Printf("Downloading latest cityMap.json from %s\n", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	// Read response body
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySHA256 checks data against a pinned hex digest
+func verifySHA256(data []byte, want string) error {
+	want = strings.ToLower(strings.TrimSpace(want))
+	if got := sha256Hex(data); got != want {
+		return fmt.Errorf("SHA-256 mismatch: got %s, pinned %s", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+// Data-quality issue categories, in report order
+const (
+	issueMissingField = "missing required field"
+	issueCoordinates  = "invalid coordinates"
+	issueTimezone     = "unresolvable timezone"
+	issuePlaceholder  = "placeholder ISO code"
+	issueISO          = "ISO code inconsistent with country"
+	issueDuplicate    = "duplicate city"
+)
+
+var issueCategories = []string{
+	issueMissingField, issueCoordinates, issueTimezone, issuePlaceholder, issueISO, issueDuplicate,
+}
+
+// maxReportExamples limits how many affected cities are listed per category
+const maxReportExamples = 5
+
+var (
+	iso2Pattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	iso3Pattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// qualityReport lists the cities affected by each data-quality issue
+type qualityReport struct {
+	records int
+	issues  map[string][]string
+}
+
+func (r *qualityReport) add(category string, city citytimezones.CityData, detail string) {
+	r.issues[category] = append(r.issues[category], fmt.Sprintf("%s: %s", citytimezones.KeyOf(city), detail))
+}
+
+// decodeStrict parses data against the CityData schema, rejecting unknown
+// fields and values of the wrong type
+func decodeStrict(data []byte) ([]citytimezones.CityData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var cities []citytimezones.CityData
+	if err := decoder.Decode(&cities); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the city array")
+	}
+	for i, city := range cities {
+		if err := checkFieldTypes(city); err != nil {
+			return nil, fmt.Errorf("record %d (%s): %w", i, city.City, err)
+		}
+	}
+	return cities, nil
+}
+
+// checkFieldTypes rejects values of the loosely typed CityData fields that
+// the schema does not allow: ISO codes must be strings or numbers, the
+// population a number or empty, and the optional names strings
+func checkFieldTypes(city citytimezones.CityData) error {
+	fields := []struct {
+		name  string
+		value interface{}
+		ok    func(interface{}) bool
+	}{
+		{"iso2", city.ISO2, isStringOrNumber},
+		{"iso3", city.ISO3, isStringOrNumber},
+		{"pop", city.Pop, isPopulation},
+		{"state_ansi", city.StateAnsi, isOptionalString},
+		{"exactCity", city.ExactCity, isOptionalString},
+		{"exactProvince", city.ExactProvince, isOptionalString},
+	}
+	for _, f := range fields {
+		if !f.ok(f.value) {
+			return fmt.Errorf("field %q has unexpected %T value %v", f.name, f.value, f.value)
+		}
+	}
+	return nil
+}
+
+func isStringOrNumber(v interface{}) bool {
+	switch v.(type) {
+	case string, float64:
+		return true
+	}
+	return false
+}
+
+func isPopulation(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, float64:
+		return true
+	case string:
+		return v == ""
+	}
+	return false
+}
+
+func isOptionalString(v interface{}) bool {
+	switch v.(type) {
+	case nil, string:
+		return true
+	}
+	return false
+}
+
+// checkQuality runs every data-quality check over the cities
+func checkQuality(cities []citytimezones.CityData) *qualityReport {
+	report := &qualityReport{records: len(cities), issues: map[string][]string{}}
+	zones := map[string]bool{}
+
+	type isoPair struct{ iso2, iso3 string }
+	countryCodes := map[string]map[isoPair]int{}
+	seen := map[string]citytimezones.CityData{}
+
+	for _, city := range cities {
+		var missing []string
+		for field, value := range map[string]string{"city": city.City, "country": city.Country, "timezone": city.Timezone} {
+			if value == "" {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			report.add(issueMissingField, city, "no "+strings.Join(missing, ", "))
+		}
+
+		if city.Lat < -90 || city.Lat > 90 || city.Lng < -180 || city.Lng > 180 {
+			report.add(issueCoordinates, city, fmt.Sprintf("%v,%v out of range", city.Lat, city.Lng))
+		}
+
+		if city.Timezone != "" {
+			if _, known := zones[city.Timezone]; !known {
+				_, err := time.LoadLocation(city.Timezone)
+				zones[city.Timezone] = err == nil
+			}
+			if !zones[city.Timezone] {
+				report.add(issueTimezone, city, fmt.Sprintf("%q", city.Timezone))
+			}
+		}
+
+		iso2, ok2 := isoCode(city.ISO2)
+		iso3, ok3 := isoCode(city.ISO3)
+		if !ok2 || !ok3 {
+			report.add(issuePlaceholder, city, fmt.Sprintf("iso2=%v iso3=%v", city.ISO2, city.ISO3))
+		} else {
+			if !iso2Pattern.MatchString(iso2) || !iso3Pattern.MatchString(iso3) {
+				report.add(issueISO, city, fmt.Sprintf("malformed iso2=%q iso3=%q", iso2, iso3))
+			}
+			if countryCodes[city.Country] == nil {
+				countryCodes[city.Country] = map[isoPair]int{}
+			}
+			countryCodes[city.Country][isoPair{iso2, iso3}]++
+		}
+
+		id := citytimezones.CityID(city)
+		if first, dup := seen[id]; dup {
+			report.add(issueDuplicate, city, fmt.Sprintf("same place as %v,%v", first.Lat, first.Lng))
+		} else {
+			seen[id] = city
+		}
+	}
+
+	// Flag cities whose codes differ from the majority for their country
+	for _, city := range cities {
+		iso2, ok2 := isoCode(city.ISO2)
+		iso3, ok3 := isoCode(city.ISO3)
+		if !ok2 || !ok3 {
+			continue
+		}
+		var majority isoPair
+		best := 0
+		for pair, n := range countryCodes[city.Country] {
+			if n > best || (n == best && pair.iso2 < majority.iso2) {
+				majority, best = pair, n
+			}
+		}
+		if (isoPair{iso2, iso3}) != majority {
+			report.add(issueISO, city, fmt.Sprintf("%s/%s, but %s mostly uses %s/%s",
+				iso2, iso3, city.Country, majority.iso2, majority.iso3))
+		}
+	}
+
+	return report
+}
+
+// isoCode returns an ISO code as a string, or false for placeholder values
+// such as -99 or an empty string
+func isoCode(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	if !ok || s == "" || s == "-99" {
+		return "", false
+	}
+	return s, true
+}
+
+// print writes the report with a few example cities per category
+func (r *qualityReport) print() {
+	fmt.Printf("Data quality report (%d records):\n", r.records)
+	for _, category := range issueCategories {
+		affected := r.issues[category]
+		fmt.Printf("  %-36s %d\n", category+":", len(affected))
+		for i, example := range affected {
+			if i == maxReportExamples {
+				fmt.Printf("      ... and %d more\n", len(affected)-maxReportExamples)
+				break
+			}
+			fmt.Printf("      %s\n", example)
+		}
+	}
+}
+
+// findRegressions compares the upstream report against the local data and
+// describes every category that got worse by more than maxNewIssues, and a
+// drop in records of more than maxRecordDrop percent
+func findRegressions(local, upstream *qualityReport, maxNewIssues int, maxRecordDrop float64) []string {
+	var regressions []string
+
+	if local.records > 0 {
+		drop := 100 * float64(local.records-upstream.records) / float64(local.records)
+		if drop > maxRecordDrop {
+			regressions = append(regressions, fmt.Sprintf("record count dropped from %d to %d (%.1f%%, limit %.1f%%)",
+				local.records, upstream.records, drop, maxRecordDrop))
+		}
+	}
+
+	for _, category := range issueCategories {
+		before, after := len(local.issues[category]), len(upstream.issues[category])
+		if after-before > maxNewIssues {
+			regressions = append(regressions, fmt.Sprintf("%s: %d -> %d (limit +%d)", category, before, after, maxNewIssues))
+		}
+	}
+
+	return regressions
+}
+
+// validateUpdate prints the quality report of the new data and, when local
+// data exists, fails if the new data regresses beyond the thresholds
+func validateUpdate(newData, localData []byte, maxNewIssues int, maxRecordDrop float64) error {
+	var cities []citytimezones.CityData
+	if err := json.Unmarshal(newData, &cities); err != nil {
+		return fmt.Errorf("failed to parse new data: %w", err)
+	}
+	upstream := checkQuality(cities)
+	upstream.print()
+
+	if localData == nil {
+		return nil
+	}
+	var localCities []citytimezones.CityData
+	if err := json.Unmarshal(localData, &localCities); err != nil {
+		return fmt.Errorf("failed to parse local data: %w", err)
+	}
+
+	regressions := findRegressions(checkQuality(localCities), upstream, maxNewIssues, maxRecordDrop)
+	if len(regressions) == 0 {
+		return nil
+	}
+	fmt.Println("Regressions against local data:")
+	for _, regression := range regressions {
+		fmt.Printf("  %s\n", regression)
+	}
+	return fmt.Errorf("%d data-quality regressions beyond thresholds", len(regressions))
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+func testCity(name, country, iso2, iso3, timezone string, lat, lng float64) citytimezones.CityData {
+	return citytimezones.CityData{
+		City: name, CityAscii: name, Country: country, ISO2: iso2, ISO3: iso3,
+		Timezone: timezone, Lat: lat, Lng: lng, Pop: float64(1000),
+	}
+}
+
+func cleanCities() []citytimezones.CityData {
+	return []citytimezones.CityData{
+		testCity("Paris", "France", "FR", "FRA", "Europe/Paris", 48.86, 2.35),
+		testCity("Lyon", "France", "FR", "FRA", "Europe/Paris", 45.76, 4.84),
+		testCity("Marseille", "France", "FR", "FRA", "Europe/Paris", 43.30, 5.37),
+		testCity("Berlin", "Germany", "DE", "DEU", "Europe/Berlin", 52.52, 13.40),
+	}
+}
+
+func marshalCities(t *testing.T, cities []citytimezones.CityData) []byte {
+	t.Helper()
+	data, err := json.Marshal(cities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecodeStrict_Valid(t *testing.T) {
+	cities, err := decodeStrict([]byte(`[{"city":"Paris","city_ascii":"Paris","lat":48.86,"lng":2.35,"pop":2138551,"country":"France","iso2":"FR","iso3":"FRA","province":"Ile-de-France","timezone":"Europe/Paris","state_ansi":null}]`))
+	if err != nil {
+		t.Fatalf("Expected valid record to decode, got error: %v", err)
+	}
+	if len(cities) != 1 || cities[0].City != "Paris" {
+		t.Errorf("Expected Paris, got %v", cities)
+	}
+}
+
+func TestDecodeStrict_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown field", `[{"city":"Paris","iso2":"FR","iso3":"FRA","pop":1,"mayor":"x"}]`},
+		{"string latitude", `[{"city":"Paris","lat":"48.86","iso2":"FR","iso3":"FRA","pop":1}]`},
+		{"object iso2", `[{"city":"Paris","iso2":{"code":"FR"},"iso3":"FRA","pop":1}]`},
+		{"boolean iso3", `[{"city":"Paris","iso2":"FR","iso3":true,"pop":1}]`},
+		{"null iso2", `[{"city":"Paris","iso2":null,"iso3":"FRA","pop":1}]`},
+		{"string population", `[{"city":"Paris","iso2":"FR","iso3":"FRA","pop":"2138551"}]`},
+		{"array population", `[{"city":"Paris","iso2":"FR","iso3":"FRA","pop":[1]}]`},
+		{"numeric state_ansi", `[{"city":"Paris","iso2":"FR","iso3":"FRA","pop":1,"state_ansi":7}]`},
+		{"trailing data", `[] []`},
+		{"not an array", `{"city":"Paris"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeStrict([]byte(tt.data)); err == nil {
+				t.Errorf("Expected %s to be rejected, got nil error", tt.name)
+			}
+		})
+	}
+}
+
+func TestDecodeStrict_AllowedLooseTypes(t *testing.T) {
+	// Upstream uses -99 for missing ISO codes and "" or null for no population
+	data := `[{"city":"Somewhere","iso2":-99,"iso3":"-99","pop":""},{"city":"Elsewhere","iso2":"XX","iso3":"XXX","pop":null}]`
+	if _, err := decodeStrict([]byte(data)); err != nil {
+		t.Errorf("Expected loose upstream types to decode, got error: %v", err)
+	}
+}
+
+func TestDecodeStrict_BundledData(t *testing.T) {
+	data, err := os.ReadFile("../../data/cityMap.json")
+	if err != nil {
+		t.Skipf("bundled data not available: %v", err)
+	}
+	if _, err := decodeStrict(data); err != nil {
+		t.Errorf("Expected bundled data to match the schema, got error: %v", err)
+	}
+}
+
+func TestIsoCode(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+		ok    bool
+	}{
+		{"FR", "FR", true},
+		{"FRA", "FRA", true},
+		{"", "", false},
+		{"-99", "", false},
+		{float64(-99), "", false},
+		{nil, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := isoCode(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("isoCode(%#v): expected %q, %v, got %q, %v", tt.value, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestCheckQuality_Clean(t *testing.T) {
+	report := checkQuality(cleanCities())
+	if report.records != 4 {
+		t.Errorf("Expected 4 records, got %d", report.records)
+	}
+	for category, affected := range report.issues {
+		if len(affected) > 0 {
+			t.Errorf("Expected no %q issues, got %v", category, affected)
+		}
+	}
+}
+
+func TestCheckQuality_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(c *citytimezones.CityData)
+		category string
+	}{
+		{"missing city", func(c *citytimezones.CityData) { c.City = "" }, issueMissingField},
+		{"missing country", func(c *citytimezones.CityData) { c.Country = "" }, issueMissingField},
+		{"missing timezone", func(c *citytimezones.CityData) { c.Timezone = "" }, issueMissingField},
+		{"latitude out of range", func(c *citytimezones.CityData) { c.Lat = 91 }, issueCoordinates},
+		{"longitude out of range", func(c *citytimezones.CityData) { c.Lng = -181 }, issueCoordinates},
+		{"unknown timezone", func(c *citytimezones.CityData) { c.Timezone = "Europe/Atlantis" }, issueTimezone},
+		{"numeric placeholder", func(c *citytimezones.CityData) { c.ISO2 = float64(-99) }, issuePlaceholder},
+		{"string placeholder", func(c *citytimezones.CityData) { c.ISO3 = "-99" }, issuePlaceholder},
+		{"empty code", func(c *citytimezones.CityData) { c.ISO2 = "" }, issuePlaceholder},
+		{"malformed code", func(c *citytimezones.CityData) { c.ISO2 = "fr" }, issueISO},
+		{"code differs from country", func(c *citytimezones.CityData) { c.ISO2, c.ISO3 = "MC", "MCO" }, issueISO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cities := cleanCities()
+			tt.mutate(&cities[0])
+			report := checkQuality(cities)
+
+			if len(report.issues[tt.category]) == 0 {
+				t.Fatalf("Expected a %q issue, got %v", tt.category, report.issues)
+			}
+			for category, affected := range report.issues {
+				if category != tt.category && len(affected) > 0 {
+					t.Errorf("Expected only %q issues, also got %q: %v", tt.category, category, affected)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckQuality_Duplicate(t *testing.T) {
+	cities := append(cleanCities(), testCity("Paris", "France", "FR", "FRA", "Europe/Paris", 48.87, 2.36))
+	report := checkQuality(cities)
+
+	affected := report.issues[issueDuplicate]
+	if len(affected) != 1 {
+		t.Fatalf("Expected 1 duplicate, got %v", affected)
+	}
+	if !strings.Contains(affected[0], "Paris") {
+		t.Errorf("Expected duplicate to name Paris, got %q", affected[0])
+	}
+}
+
+func TestFindRegressions(t *testing.T) {
+	report := func(records, duplicates int) *qualityReport {
+		r := &qualityReport{records: records, issues: map[string][]string{}}
+		for i := 0; i < duplicates; i++ {
+			r.issues[issueDuplicate] = append(r.issues[issueDuplicate], "dup")
+		}
+		return r
+	}
+
+	tests := []struct {
+		name          string
+		local         *qualityReport
+		upstream      *qualityReport
+		maxNewIssues  int
+		maxRecordDrop float64
+		want          int
+	}{
+		{"unchanged", report(100, 2), report(100, 2), 0, 1, 0},
+		{"fewer issues", report(100, 2), report(100, 0), 0, 1, 0},
+		{"new issue", report(100, 2), report(100, 3), 0, 1, 1},
+		{"new issues within limit", report(100, 2), report(100, 4), 2, 1, 0},
+		{"new issues past limit", report(100, 2), report(100, 5), 2, 1, 1},
+		{"records grew", report(100, 0), report(150, 0), 0, 1, 0},
+		{"drop within limit", report(100, 0), report(99, 0), 0, 1, 0},
+		{"drop past limit", report(100, 0), report(98, 0), 0, 1, 1},
+		{"drop and new issue", report(100, 0), report(90, 1), 0, 1, 2},
+		{"no local records", report(0, 0), report(10, 0), 0, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findRegressions(tt.local, tt.upstream, tt.maxNewIssues, tt.maxRecordDrop)
+			if len(got) != tt.want {
+				t.Errorf("Expected %d regressions, got %d: %v", tt.want, len(got), got)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate_Thresholds(t *testing.T) {
+	local := marshalCities(t, cleanCities())
+
+	withBadZone := cleanCities()
+	withBadZone[1].Timezone = "Europe/Atlantis"
+	badZone := marshalCities(t, withBadZone)
+
+	shrunk := marshalCities(t, cleanCities()[:2])
+
+	tests := []struct {
+		name          string
+		newData       []byte
+		localData     []byte
+		maxNewIssues  int
+		maxRecordDrop float64
+		wantErr       bool
+	}{
+		{"first sync", badZone, nil, 0, 1, false},
+		{"no regression", local, local, 0, 1, false},
+		{"new issue", badZone, local, 0, 1, true},
+		{"new issue allowed", badZone, local, 1, 1, false},
+		{"records dropped", shrunk, local, 0, 1, true},
+		{"records drop allowed", shrunk, local, 0, 50, false},
+		{"unparsable new data", []byte("{"), local, 0, 1, true},
+		{"unparsable local data", local, []byte("{"), 0, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateUpdate(tt.newData, tt.localData, tt.maxNewIssues, tt.maxRecordDrop)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
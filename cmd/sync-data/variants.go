@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	citytimezones "github.com/justcfx2u/city-timezones-go"
+)
+
+const (
+	localBinaryPath    = "data/cityMap.bin"
+	localSmallGZPath   = "data/cityMap.small.json.gz"
+	smallMinPopulation = 100000
+)
+
+// writeBinaryDataset writes the default embedded dataset in the compact
+// binary format
+func writeBinaryDataset(data []byte, dst string) error {
+	var cities []citytimezones.CityData
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return fmt.Errorf("failed to parse city data: %w", err)
+	}
+
+	encoded, err := citytimezones.EncodeBinary(cities)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, encoded, 0644)
+}
+
+// writeSmallDataset writes the dataset embedded by the citytz_small build tag:
+// compact JSON of the cities with at least smallMinPopulation inhabitants
+func writeSmallDataset(data []byte, dst string) (int, error) {
+	var cities []json.RawMessage
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return 0, fmt.Errorf("failed to parse city data: %w", err)
+	}
+
+	var small []json.RawMessage
+	for _, raw := range cities {
+		var city struct {
+			Pop interface{} `json:"pop"`
+		}
+		if err := json.Unmarshal(raw, &city); err != nil {
+			return 0, fmt.Errorf("failed to parse city: %w", err)
+		}
+		if pop, ok := city.Pop.(float64); ok && pop >= smallMinPopulation {
+			small = append(small, raw)
+		}
+	}
+
+	// Marshaling RawMessage compacts it, which keeps the small variant small
+	compact, err := json.Marshal(small)
+	if err != nil {
+		return 0, err
+	}
+	return len(small), compressData(compact, dst)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const localWindowsZonesPath = "data/windowsZones.txt"
+
+// cldrWindowsZones is the part of CLDR's supplemental windowsZones.xml that
+// maps Windows zone IDs to IANA zones per territory
+type cldrWindowsZones struct {
+	Version struct {
+		Number string `xml:"number,attr"`
+	} `xml:"version"`
+	MapZones []struct {
+		Other     string `xml:"other,attr"`
+		Territory string `xml:"territory,attr"`
+		Type      string `xml:"type,attr"`
+	} `xml:"windowsZones>mapTimezones>mapZone"`
+}
+
+// runWindowsZones regenerates the embedded Windows zone table from a local
+// copy of CLDR's common/supplemental/windowsZones.xml
+func runWindowsZones(args []string) {
+	flags := flag.NewFlagSet("windows-zones", flag.ExitOnError)
+	cldr := flags.String("cldr", "windowsZones.xml", "CLDR windowsZones.xml file to read")
+	release := flags.String("release", "", "CLDR release the file comes from, e.g. 43.0, recorded in the header")
+	output := flags.String("output", localWindowsZonesPath, "Windows zone table to write")
+	flags.Parse(args)
+
+	data, err := os.ReadFile(*cldr)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to read %s: %v\n", *cldr, err)
+		os.Exit(1)
+	}
+	var zones cldrWindowsZones
+	if err := xml.Unmarshal(data, &zones); err != nil {
+		fmt.Printf("ERROR: Failed to parse %s: %v\n", *cldr, err)
+		os.Exit(1)
+	}
+	if len(zones.MapZones) == 0 {
+		fmt.Printf("ERROR: No mapZone elements found in %s\n", *cldr)
+		os.Exit(1)
+	}
+
+	revision := cldrVersion(zones.Version.Number)
+	if *release == "" && revision == "" {
+		fmt.Printf("ERROR: %s has no revision; pass the CLDR release with -release\n", *cldr)
+		os.Exit(1)
+	}
+
+	var lines []string
+	for _, z := range zones.MapZones {
+		lines = append(lines, strings.Join([]string{z.Other, z.Territory, strings.Join(strings.Fields(z.Type), " ")}, "\t"))
+	}
+	sort.Strings(lines)
+
+	var b strings.Builder
+	source := "CLDR"
+	if *release != "" {
+		source += " " + *release
+	}
+	fmt.Fprintf(&b, "# Windows zone IDs from %s windowsZones.xml%s.\n", source, revision)
+	b.WriteString("# Tab-separated: Windows ID, ISO territory (001 = default), IANA zones.\n")
+	command := "go run ./cmd/sync-data windows-zones -cldr windowsZones.xml"
+	if *release != "" {
+		command += " -release " + *release
+	}
+	b.WriteString("# Generated by: " + command + "\n")
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+
+	if err := os.WriteFile(*output, []byte(b.String()), 0644); err != nil {
+		fmt.Printf("ERROR: Failed to write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d Windows zone mappings to %s\n", len(lines), *output)
+}
+
+func cldrVersion(version string) string {
+	version = strings.Trim(strings.TrimPrefix(version, "$Revision"), " :$")
+	if version == "" {
+		return ""
+	}
+	return " (revision " + version + ")"
+}
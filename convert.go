@@ -0,0 +1,115 @@
+package citytimezones
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrCityNotFound is returned when a city query matches no city
+var ErrCityNotFound = errors.New("city not found")
+
+// AmbiguousCityError is returned when a city query matches more than one
+// city, such as "Springfield". Narrow the query with a province or country
+// ("springfield mo"), or pass a city ID.
+type AmbiguousCityError struct {
+	Query      string
+	Candidates []CityData
+}
+
+func (e *AmbiguousCityError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = fmt.Sprintf("%s (%s)", KeyOf(c), c.Timezone)
+	}
+	return fmt.Sprintf("ambiguous city %q: %d candidates: %s", e.Query, len(e.Candidates), strings.Join(names, "; "))
+}
+
+// ResolveCity resolves a city query to a single city
+func ResolveCity(query string) (CityData, error) {
+	return Default().ResolveCity(query)
+}
+
+// ResolveCity resolves a city query to a single city in the database. The
+// query is tried as a city ID, then as an exact city name (LookupViaCity),
+// then as a partial match (FindFromCityStateProvince). A query matching more
+// than one distinct city yields an *AmbiguousCityError, even when they share
+// a timezone.
+func (db *Database) ResolveCity(query string) (CityData, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return CityData{}, fmt.Errorf("%w: %w", ErrCityNotFound, ErrEmptyQuery)
+	}
+	candidates := db.cityCandidates(query)
+	if len(candidates) == 0 {
+		return CityData{}, fmt.Errorf("%w: %q", ErrCityNotFound, query)
+	}
+
+	if len(candidates) > 1 {
+		return CityData{}, &AmbiguousCityError{Query: query, Candidates: candidates}
+	}
+	return candidates[0], nil
+}
+
+// cityCandidates returns the cities a query names: the city with that ID,
+// else exact name matches, else partial matches
+func (db *Database) cityCandidates(query string) []CityData {
+	if c, ok := db.GetByID(query); ok {
+		return []CityData{c}
+	}
+	candidates := db.LookupViaCity(query)
+	if len(candidates) == 0 {
+		candidates = db.FindFromCityStateProvince(query)
+	}
+	return candidates
+}
+
+// ConvertTime converts a wall-clock time in one city to the same instant in
+// another city
+func ConvertTime(t time.Time, fromCity, toCity string) (time.Time, error) {
+	return Default().ConvertTime(t, fromCity, toCity)
+}
+
+// ConvertTime converts a wall-clock time in one city of the database to the
+// same instant in another. Cities are resolved with ResolveCity.
+func (db *Database) ConvertTime(t time.Time, fromCity, toCity string) (time.Time, error) {
+	from, err := db.ResolveCity(fromCity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	to, err := db.ResolveCity(toCity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ConvertTimeBetween(t, from, to)
+}
+
+// ConvertTimeBetween converts a wall-clock time in one city to the same
+// instant in another. Only the date and clock reading of t are used; its
+// location is replaced by from's timezone, so 15:00 means 15:00 in from.
+func ConvertTimeBetween(t time.Time, from, to CityData) (time.Time, error) {
+	fromLoc, err := cityLocation(from)
+	if err != nil {
+		return time.Time{}, err
+	}
+	toLoc, err := cityLocation(to)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), fromLoc)
+	return local.In(toLoc), nil
+}
+
+// cityLocation loads the timezone of a city
+func cityLocation(c CityData) (*time.Location, error) {
+	if c.Timezone == "" {
+		return nil, fmt.Errorf("%s has no timezone", KeyOf(c))
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timezone of %s: %w", KeyOf(c), err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,126 @@
+//go:build !citytz_small && !citytz_large
+
+package citytimezones
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConvertTime(t *testing.T) {
+	// 15:00 in Chicago (CST, UTC-6) is 02:30 the next day in Mumbai (UTC+5:30)
+	got, err := ConvertTime(time.Date(2024, 1, 15, 15, 0, 0, 0, time.UTC), "Chicago", "Mumbai")
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got error: %v", err)
+	}
+	if got.Location().String() != "Asia/Kolkata" {
+		t.Errorf("Expected Asia/Kolkata, got %s", got.Location())
+	}
+	if got.Day() != 16 || got.Hour() != 2 || got.Minute() != 30 {
+		t.Errorf("Expected 2024-01-16 02:30, got %s", got)
+	}
+}
+
+func TestConvertTime_DST(t *testing.T) {
+	// In July Chicago is on CDT (UTC-5)
+	got, err := ConvertTime(time.Date(2024, 7, 15, 15, 0, 0, 0, time.UTC), "Chicago", "Mumbai")
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got error: %v", err)
+	}
+	if got.Day() != 16 || got.Hour() != 1 || got.Minute() != 30 {
+		t.Errorf("Expected 2024-07-16 01:30, got %s", got)
+	}
+}
+
+func TestConvertTime_Ambiguous(t *testing.T) {
+	_, err := ConvertTime(time.Now(), "Springfield", "Mumbai")
+
+	var ambiguous *AmbiguousCityError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected *AmbiguousCityError, got %v", err)
+	}
+	if len(ambiguous.Candidates) < 2 {
+		t.Errorf("Expected several candidates, got %d", len(ambiguous.Candidates))
+	}
+	for _, c := range ambiguous.Candidates {
+		if c.City != "Springfield" {
+			t.Errorf("Expected only Springfields, got %s", c.City)
+		}
+	}
+}
+
+func TestConvertTime_Narrowed(t *testing.T) {
+	got, err := ConvertTime(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), "springfield mo", "Chicago")
+	if err != nil {
+		t.Fatalf("Expected narrowed query to resolve, got error: %v", err)
+	}
+	if got.Hour() != 12 {
+		t.Errorf("Expected 12:00 within the same zone, got %s", got)
+	}
+}
+
+func TestConvertTime_NotFound(t *testing.T) {
+	_, err := ConvertTime(time.Now(), "Atlantis", "Chicago")
+	if !errors.Is(err, ErrCityNotFound) {
+		t.Errorf("Expected ErrCityNotFound, got %v", err)
+	}
+}
+
+func TestResolveCity_ByID(t *testing.T) {
+	springfield := FindFromCityStateProvince("springfield oregon")[0]
+
+	got, err := ResolveCity(springfield.ID)
+	if err != nil {
+		t.Fatalf("Expected ID to resolve, got error: %v", err)
+	}
+	if got.Province != "Oregon" {
+		t.Errorf("Expected Springfield, Oregon, got %s", KeyOf(got))
+	}
+}
+
+func TestConvertTimeBetween(t *testing.T) {
+	from := CityData{City: "Tokyo", Timezone: "Asia/Tokyo"}
+	to := CityData{City: "London", Timezone: "Europe/London"}
+
+	got, err := ConvertTimeBetween(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), from, to)
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got error: %v", err)
+	}
+	if got.Day() != 1 || got.Hour() != 0 {
+		t.Errorf("Expected 2024-03-01 00:00, got %s", got)
+	}
+
+	if _, err := ConvertTimeBetween(time.Now(), CityData{City: "Nowhere"}, to); err == nil {
+		t.Error("Expected error for a city without timezone")
+	}
+}
+
+func TestResolveCity_SameZoneAmbiguous(t *testing.T) {
+	db := NewDatabase([]CityData{
+		{City: "Portland", Province: "Oregon", Country: "United States of America", Lat: 45.52, Lng: -122.68, Timezone: "America/Los_Angeles"},
+		{City: "Portland", Province: "Washington", Country: "United States of America", Lat: 45.6, Lng: -122.5, Timezone: "America/Los_Angeles"},
+	})
+
+	_, err := db.ResolveCity("Portland")
+	var ambiguous *AmbiguousCityError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected *AmbiguousCityError for cities sharing a zone, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 {
+		t.Errorf("Expected 2 candidates, got %d", len(ambiguous.Candidates))
+	}
+
+	city, err := db.ResolveCity("Portland Oregon")
+	if err != nil {
+		t.Fatalf("Expected narrowed query to resolve, got error: %v", err)
+	}
+	if city.Province != "Oregon" {
+		t.Errorf("Expected Portland, Oregon, got %s", KeyOf(city))
+	}
+
+	city, err = db.ResolveCity(ambiguous.Candidates[1].ID)
+	if err != nil || city.Province != "Washington" {
+		t.Errorf("Expected the ID to resolve Portland, Washington, got %s, %v", KeyOf(city), err)
+	}
+}
@@ -0,0 +1,289 @@
+package citytimezones
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedCoordinates is returned for coordinate values that no
+// parser handles
+var ErrUnsupportedCoordinates = errors.New("unsupported coordinate type")
+
+// CoordinateParser converts coordinates of some type into a latitude and
+// longitude. ParseCoordinates returns ErrUnsupportedCoordinates for values
+// it does not handle, so that the next parser is tried, and any other error
+// for values it handles but cannot parse.
+type CoordinateParser interface {
+	ParseCoordinates(coords interface{}) (lat, lng float64, err error)
+}
+
+// CoordinateParserFunc adapts a function to a CoordinateParser
+type CoordinateParserFunc func(coords interface{}) (lat, lng float64, err error)
+
+// ParseCoordinates calls f(coords)
+func (f CoordinateParserFunc) ParseCoordinates(coords interface{}) (lat, lng float64, err error) {
+	return f(coords)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   []CoordinateParser
+
+	// builtinParsers handle strings, [2]float64 and []float64, after any
+	// registered parsers
+	builtinParsers = []CoordinateParser{
+		CoordinateParserFunc(parseStringCoordinates),
+		CoordinateParserFunc(parseFloatCoordinates),
+	}
+)
+
+// RegisterParser adds a parser for FindFromCoordinates. Registered parsers
+// are tried in the order they were registered, before the built-in ones, so
+// they can also take over strings. It is safe to call concurrently with
+// lookups, though parsers are usually registered in init.
+func RegisterParser(p CoordinateParser) {
+	if p == nil {
+		panic("citytimezones: RegisterParser with nil parser")
+	}
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers = append(parsers, p)
+}
+
+// parseCoordinates parses coords with the first parser that handles its type
+func parseCoordinates(coords interface{}) (lat, lng float64, err error) {
+	parsersMu.RLock()
+	registered := parsers
+	parsersMu.RUnlock()
+
+	for _, list := range [][]CoordinateParser{registered, builtinParsers} {
+		for _, p := range list {
+			lat, lng, err = p.ParseCoordinates(coords)
+			if !errors.Is(err, ErrUnsupportedCoordinates) {
+				return lat, lng, err
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: %T", ErrUnsupportedCoordinates, coords)
+}
+
+func parseStringCoordinates(coords interface{}) (lat, lng float64, err error) {
+	s, ok := coords.(string)
+	if !ok {
+		return 0, 0, ErrUnsupportedCoordinates
+	}
+	// "lat,lng", or DMS, UTM, MGRS or a Maidenhead locator
+	return parseCoordinateString(s)
+}
+
+func parseFloatCoordinates(coords interface{}) (lat, lng float64, err error) {
+	switch v := coords.(type) {
+	case [2]float64:
+		return v[0], v[1], nil
+	case []float64:
+		if len(v) != 2 {
+			return 0, 0, fmt.Errorf("coordinate slice must have exactly 2 elements")
+		}
+		return v[0], v[1], nil
+	default:
+		return 0, 0, ErrUnsupportedCoordinates
+	}
+}
+
+// Coordinate string formats besides "lat,lng". Each parser returns the
+// center of the area the input denotes.
+
+var (
+	// dmsPrefixPattern matches one coordinate with the hemisphere first,
+	// e.g. N41°49′30″
+	dmsPrefixPattern = regexp.MustCompile(`([NSEWnsew])\s*(\d+(?:\.\d+)?)\s*[°º]\s*(?:(\d+(?:\.\d+)?)\s*['′]\s*)?(?:(\d+(?:\.\d+)?)\s*(?:"|″|'')\s*)?`)
+
+	// dmsSuffixPattern matches one coordinate with the hemisphere last,
+	// e.g. 41°49′30″N
+	dmsSuffixPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*[°º]\s*(?:(\d+(?:\.\d+)?)\s*['′]\s*)?(?:(\d+(?:\.\d+)?)\s*(?:"|″|'')\s*)?([NSEWnsew])`)
+
+	maidenheadPattern = regexp.MustCompile(`^[A-Za-z]{2}(?:\d[A-Za-z0-9]*)?$`)
+	mgrsPattern       = regexp.MustCompile(`^\d{1,2}\s*[C-HJ-NP-Xc-hj-np-x]\s*[A-HJ-NP-Za-hj-np-z]{2}\s*\d*\s*\d*$`)
+	utmPattern        = regexp.MustCompile(`^\d{1,2}\s*[A-Za-z]\s+\d+(?:\.\d+)?\s*[Ee]?\s+\d+(?:\.\d+)?\s*[Nn]?$`)
+)
+
+// parseCoordinateString parses "lat,lng" or, failing that, a string in one
+// of the other supported formats, recognized by its shape so that the error
+// describes what is wrong with the intended format
+func parseCoordinateString(s string) (lat, lng float64, err error) {
+	trimmed := strings.TrimSpace(s)
+	switch {
+	case strings.ContainsAny(trimmed, "°º"):
+		return ParseDMS(trimmed)
+	case mgrsPattern.MatchString(trimmed):
+		return ParseMGRS(trimmed)
+	case utmPattern.MatchString(trimmed):
+		return ParseUTM(trimmed)
+	case maidenheadPattern.MatchString(trimmed):
+		return ParseMaidenhead(trimmed)
+	}
+	return parseDecimalCoordinates(s)
+}
+
+// parseDecimalCoordinates parses the "lat,lng" format
+func parseDecimalCoordinates(s string) (lat, lng float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid coordinate string format, expected 'lat,lng'")
+	}
+
+	lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude: %v", err)
+	}
+
+	lng, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude: %v", err)
+	}
+
+	return lat, lng, nil
+}
+
+// ParseMaidenhead parses a Maidenhead grid locator of 2, 4, 6 or 8
+// characters, such as "EN61" or "EN61ev", and returns the center of the
+// square it names
+func ParseMaidenhead(locator string) (lat, lng float64, err error) {
+	s := strings.TrimSpace(locator)
+	if n := len(s); n == 0 || n%2 != 0 || n > 8 {
+		return 0, 0, fmt.Errorf("invalid Maidenhead locator %q: must have 2, 4, 6 or 8 characters", locator)
+	}
+
+	// Each pair refines longitude and latitude: fields of 20°x10° (A-R),
+	// squares of 2°x1° (0-9), subsquares of 5'x2.5' (a-x), and extended
+	// squares of 30"x15" (0-9)
+	lng, lat = -180, -90
+	lngSize, latSize := 360.0, 180.0
+	for i := 0; i < len(s); i += 2 {
+		var lo, hi byte
+		var divisions float64
+		switch i {
+		case 0:
+			lo, hi, divisions = 'A', 'R', 18
+		case 4:
+			lo, hi, divisions = 'A', 'X', 24
+		default:
+			lo, hi, divisions = '0', '9', 10
+		}
+
+		lngSize /= divisions
+		latSize /= divisions
+		for j, target := range []*float64{&lng, &lat} {
+			c := s[i+j]
+			if lo == 'A' && c >= 'a' && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			if c < lo || c > hi {
+				return 0, 0, fmt.Errorf("invalid Maidenhead locator %q: character %d (%q) must be %c-%c", locator, i+j+1, s[i+j], lo, hi)
+			}
+			size := lngSize
+			if j == 1 {
+				size = latSize
+			}
+			*target += float64(c-lo) * size
+		}
+	}
+
+	return lat + latSize/2, lng + lngSize/2, nil
+}
+
+// ParseDMS parses a latitude and longitude in degrees, minutes and seconds,
+// such as "41°49′N 87°45′W", "41°49'30\"N, 87°45'W" or "N41°49.5′ W87°45′".
+// Minutes and seconds are optional and may be fractional; each coordinate
+// needs a hemisphere letter, all before or all after the numbers.
+func ParseDMS(s string) (lat, lng float64, err error) {
+	trimmed := strings.TrimSpace(s)
+	pattern, prefix := dmsSuffixPattern, false
+	if trimmed != "" && strings.ContainsRune("NSEWnsew", rune(trimmed[0])) {
+		pattern, prefix = dmsPrefixPattern, true
+	}
+
+	matches := pattern.FindAllStringSubmatchIndex(trimmed, -1)
+	if len(matches) != 2 {
+		return 0, 0, fmt.Errorf("invalid DMS coordinates %q: expected a latitude and a longitude, each with a hemisphere (N/S, E/W)", s)
+	}
+	if rest := strings.Trim(trimmed[:matches[0][0]]+trimmed[matches[0][1]:matches[1][0]]+trimmed[matches[1][1]:], " ,;\t"); rest != "" {
+		return 0, 0, fmt.Errorf("invalid DMS coordinates %q: unexpected %q", s, rest)
+	}
+
+	var haveLat, haveLng bool
+	for _, m := range matches {
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return trimmed[m[2*i]:m[2*i+1]]
+		}
+		var hemisphere, degrees, minutes, seconds string
+		if prefix {
+			hemisphere, degrees, minutes, seconds = group(1), group(2), group(3), group(4)
+		} else {
+			degrees, minutes, seconds, hemisphere = group(1), group(2), group(3), group(4)
+		}
+
+		value, err := dmsValue(degrees, minutes, seconds)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid DMS coordinates %q: %v", s, err)
+		}
+		switch strings.ToUpper(hemisphere) {
+		case "S", "W":
+			value = -value
+		}
+
+		switch strings.ToUpper(hemisphere) {
+		case "N", "S":
+			if haveLat {
+				return 0, 0, fmt.Errorf("invalid DMS coordinates %q: two latitudes", s)
+			}
+			if value < -90 || value > 90 {
+				return 0, 0, fmt.Errorf("invalid DMS coordinates %q: latitude %v out of range", s, value)
+			}
+			lat, haveLat = value, true
+		default:
+			if haveLng {
+				return 0, 0, fmt.Errorf("invalid DMS coordinates %q: two longitudes", s)
+			}
+			if value < -180 || value > 180 {
+				return 0, 0, fmt.Errorf("invalid DMS coordinates %q: longitude %v out of range", s, value)
+			}
+			lng, haveLng = value, true
+		}
+	}
+	return lat, lng, nil
+}
+
+// dmsValue combines degrees, minutes and seconds into decimal degrees
+func dmsValue(degrees, minutes, seconds string) (float64, error) {
+	value, err := strconv.ParseFloat(degrees, 64)
+	if err != nil {
+		return 0, fmt.Errorf("degrees %q: %v", degrees, err)
+	}
+	for i, part := range []string{minutes, seconds} {
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q: %v", part, err)
+		}
+		if v >= 60 {
+			unit := []string{"minutes", "seconds"}[i]
+			return 0, fmt.Errorf("%s %v must be below 60", unit, v)
+		}
+		if i == 0 {
+			value += v / 60
+		} else {
+			value += v / 3600
+		}
+	}
+	return value, nil
+}
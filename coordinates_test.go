@@ -0,0 +1,293 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func assertLatLng(t *testing.T, input string, lat, lng, wantLat, wantLng, tolerance float64) {
+	t.Helper()
+	if math.Abs(lat-wantLat) > tolerance || math.Abs(lng-wantLng) > tolerance {
+		t.Errorf("%s: expected %.5f,%.5f, got %.5f,%.5f", input, wantLat, wantLng, lat, lng)
+	}
+}
+
+func TestParseMaidenhead(t *testing.T) {
+	tests := []struct {
+		locator          string
+		wantLat, wantLng float64
+	}{
+		{"EN", 45, -90},
+		{"EN61", 41.5, -87},
+		{"EN61ev", 41.895833, -87.625},
+		{"en61EV", 41.895833, -87.625},
+		{"FN31pr", 41.729167, -72.708333},
+		{"FN31pr52", 41.71875, -72.704167},
+	}
+	for _, tt := range tests {
+		lat, lng, err := ParseMaidenhead(tt.locator)
+		if err != nil {
+			t.Errorf("%s: expected locator to parse, got error: %v", tt.locator, err)
+			continue
+		}
+		assertLatLng(t, tt.locator, lat, lng, tt.wantLat, tt.wantLng, 1e-5)
+	}
+
+	invalid := map[string]string{
+		"":          "2, 4, 6 or 8 characters",
+		"EN6":       "2, 4, 6 or 8 characters",
+		"ZZ00":      "character 1",
+		"ENX1":      "character 3",
+		"EN61zz":    "character 5",
+		"EN61ev1a":  "character 8",
+		"EN61ev12x": "2, 4, 6 or 8 characters",
+	}
+	for locator, want := range invalid {
+		_, _, err := ParseMaidenhead(locator)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error mentioning %q, got %v", locator, want, err)
+		}
+	}
+}
+
+func TestParseUTM(t *testing.T) {
+	// Chicago, in zone 16 band T
+	lat, lng, err := ParseUTM("16T 448251 4636135")
+	if err != nil {
+		t.Fatalf("Expected UTM to parse, got error: %v", err)
+	}
+	assertLatLng(t, "16T 448251 4636135", lat, lng, 41.87544, -87.62364, 1e-4)
+
+	lat, lng, err = ParseUTM("16 T 448251E 4636135N")
+	if err != nil {
+		t.Fatalf("Expected UTM with suffixes to parse, got error: %v", err)
+	}
+	assertLatLng(t, "16 T 448251E 4636135N", lat, lng, 41.87544, -87.62364, 1e-4)
+
+	// Sydney, in the southern hemisphere
+	lat, lng, err = ParseUTM("56H 334786 6252080")
+	if err != nil {
+		t.Fatalf("Expected southern UTM to parse, got error: %v", err)
+	}
+	assertLatLng(t, "56H 334786 6252080", lat, lng, -33.8587, 151.2140, 1e-3)
+
+	invalid := map[string]string{
+		"16T 448251":          "expected zone and band",
+		"61T 448251 4636135":  "must be 1-60",
+		"16A 448251 4636135":  "latitude band",
+		"16T 48251 4636135":   "easting",
+		"16T 448251 46361350": "northing",
+		"16T 448251 1000000":  "outside band T",
+		"60X 900000 9000000":  "outside zone 60",
+		"32X 448251 8000000":  "zone 32X is not used",
+	}
+	for input, want := range invalid {
+		_, _, err := ParseUTM(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error mentioning %q, got %v", input, want, err)
+		}
+	}
+
+	// Within zone 1's tolerance, but west of the antimeridian
+	if _, _, err := ParseUTM("1N 138210 100000"); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates for a point off the globe, got %v", err)
+	}
+}
+
+func TestParseMGRS(t *testing.T) {
+	// The Statue of Liberty
+	for _, input := range []string{"18TWL8073504695", "18T WL 80735 04695", "18twl8073504695"} {
+		lat, lng, err := ParseMGRS(input)
+		if err != nil {
+			t.Errorf("%s: expected MGRS to parse, got error: %v", input, err)
+			continue
+		}
+		assertLatLng(t, input, lat, lng, 40.68920, -74.04450, 1e-4)
+	}
+
+	// Lower precision returns the center of a larger square
+	lat, lng, err := ParseMGRS("18TWL80")
+	if err != nil {
+		t.Fatalf("Expected 10km MGRS to parse, got error: %v", err)
+	}
+	assertLatLng(t, "18TWL80", lat, lng, 40.69, -74.04, 0.1)
+
+	// Zone 16 uses the even row letter set
+	lat, lng, err = ParseMGRS("16TDM4825136135")
+	if err != nil {
+		t.Fatalf("Expected MGRS to parse, got error: %v", err)
+	}
+	assertLatLng(t, "16TDM4825136135", lat, lng, 41.87544, -87.62364, 1e-4)
+
+	invalid := map[string]string{
+		"18T":             "100km square",
+		"18TWL807350469":  "same number of digits",
+		"18TAL8073504695": "column letter",
+		"18TWL80735O4695": "not a digit",
+		"18AWL8073504695": "latitude band",
+		"1CAA":            "outside zone 1",
+		"1C AA":           "outside zone 1",
+		"18TWA":           "row letter A is not used in band T",
+		"18TWV8073504695": "row letter V",
+		"32XMG":           "zone 32X is not used",
+	}
+	for input, want := range invalid {
+		_, _, err := ParseMGRS(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error mentioning %q, got %v", input, want, err)
+		}
+	}
+
+	// Half of this edge square lies past the antimeridian, and so does its center
+	if _, _, err := ParseMGRS("1NAA"); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates for a center past the antimeridian, got %v", err)
+	}
+
+	// Norway's zone 32V starts at 3°E instead of 6°E
+	lat, lng, err = ParseMGRS("32VKM")
+	if err != nil {
+		t.Fatalf("Expected 32VKM to parse, got error: %v", err)
+	}
+	assertLatLng(t, "32VKM", lat, lng, 59.91, 4.53, 0.01)
+}
+
+func TestParseDMS(t *testing.T) {
+	tests := []struct {
+		input            string
+		wantLat, wantLng float64
+	}{
+		{"41°49′N 87°45′W", 41.816667, -87.75},
+		{`41°49'30"N, 87°45'W`, 41.825, -87.75},
+		{"41°49′30″N 87°45′0″W", 41.825, -87.75},
+		{"N41°49.5′ W87°45′", 41.825, -87.75},
+		{"33°52′S 151°12′E", -33.866667, 151.2},
+		{"87°45′W 41°49′N", 41.816667, -87.75},
+		{"41.5°N 87.25°W", 41.5, -87.25},
+	}
+	for _, tt := range tests {
+		lat, lng, err := ParseDMS(tt.input)
+		if err != nil {
+			t.Errorf("%s: expected DMS to parse, got error: %v", tt.input, err)
+			continue
+		}
+		assertLatLng(t, tt.input, lat, lng, tt.wantLat, tt.wantLng, 1e-5)
+	}
+
+	invalid := map[string]string{
+		"41°49′N":             "a latitude and a longitude",
+		"41°49′N 87°45′N":     "two latitudes",
+		"95°N 87°W":           "out of range",
+		"41°75′N 87°45′W":     "below 60",
+		"41°49′N 87°45′W foo": "unexpected",
+	}
+	for input, want := range invalid {
+		_, _, err := ParseDMS(input)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error mentioning %q, got %v", input, want, err)
+		}
+	}
+}
+
+func TestParseCoordinates_Formats(t *testing.T) {
+	for _, input := range []string{"41°49′N 87°45′W", "16T 448251 4636135", "16TDM4825136135", "EN61ev"} {
+		lat, lng, err := parseCoordinates(input)
+		if err != nil {
+			t.Errorf("%s: expected coordinates to parse, got error: %v", input, err)
+			continue
+		}
+		assertLatLng(t, input, lat, lng, 41.85, -87.7, 0.2)
+	}
+
+	// The error describes the intended format
+	if _, _, err := parseCoordinates("EN6"); err == nil || !strings.Contains(err.Error(), "Maidenhead") {
+		t.Errorf("Expected Maidenhead error, got %v", err)
+	}
+}
+
+type testPoint struct {
+	Lat, Lng float64
+}
+
+type testGeoJSONPoint []byte
+
+func init() {
+	RegisterParser(CoordinateParserFunc(func(coords interface{}) (float64, float64, error) {
+		p, ok := coords.(testPoint)
+		if !ok {
+			return 0, 0, ErrUnsupportedCoordinates
+		}
+		return p.Lat, p.Lng, nil
+	}))
+	RegisterParser(CoordinateParserFunc(func(coords interface{}) (float64, float64, error) {
+		data, ok := coords.(testGeoJSONPoint)
+		if !ok {
+			return 0, 0, ErrUnsupportedCoordinates
+		}
+		var point struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		}
+		if err := json.Unmarshal(data, &point); err != nil {
+			return 0, 0, err
+		}
+		if point.Type != "Point" || len(point.Coordinates) < 2 {
+			return 0, 0, errors.New("expected a GeoJSON Point")
+		}
+		return point.Coordinates[1], point.Coordinates[0], nil
+	}))
+}
+
+func TestRegisterParser_Struct(t *testing.T) {
+	cities := FindFromCoordinates(testPoint{Lat: 41.8299, Lng: -87.7500})
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago from registered struct parser, got %v", cityNames(cities))
+	}
+}
+
+func TestRegisterParser_GeoJSONPoint(t *testing.T) {
+	cities, err := FindFromCoordinatesE(testGeoJSONPoint(`{"type":"Point","coordinates":[-87.75,41.8299]}`))
+	if err != nil {
+		t.Fatalf("Expected GeoJSON point to parse, got error: %v", err)
+	}
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago from GeoJSON point, got %v", cityNames(cities))
+	}
+
+	_, err = FindFromCoordinatesE(testGeoJSONPoint(`{"type":"LineString","coordinates":[]}`))
+	if err == nil || errors.Is(err, ErrUnsupportedCoordinates) {
+		t.Errorf("Expected the parser's error for a malformed point, got %v", err)
+	}
+}
+
+func TestFindFromCoordinatesE(t *testing.T) {
+	cities, err := FindFromCoordinatesE("41.8299,-87.7500")
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities near Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	_, err = FindFromCoordinatesE(42)
+	if !errors.Is(err, ErrUnsupportedCoordinates) {
+		t.Errorf("Expected ErrUnsupportedCoordinates for an int, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "int") {
+		t.Errorf("Expected the error to name the type, got %v", err)
+	}
+
+	_, err = FindFromCoordinatesE("91.5;abc")
+	if err == nil || errors.Is(err, ErrUnsupportedCoordinates) {
+		t.Errorf("Expected a parse error for a malformed string, got %v", err)
+	}
+
+	_, err = FindFromCoordinatesE([]float64{1})
+	if err == nil || !strings.Contains(err.Error(), "2 elements") {
+		t.Errorf("Expected a slice length error, got %v", err)
+	}
+
+	// The non-E form still swallows the error
+	if cities := FindFromCoordinates(42); cities == nil || len(cities) != 0 {
+		t.Errorf("Expected an empty non-nil slice, got %v", cities)
+	}
+}
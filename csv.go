@@ -0,0 +1,215 @@
+package citytimezones
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions controls how LoadCSV reads a delimited city dataset
+type CSVOptions struct {
+	// Comma is the field delimiter. Defaults to ',' (LoadTSV uses '\t').
+	Comma rune
+
+	// Columns maps CityData fields, named by their JSON keys ("city", "lat",
+	// "timezone", ...), to header names in the file. Fields that are not
+	// listed are read from a column with the same name as the field, if the
+	// file has one.
+	Columns map[string]string
+}
+
+// cityFields lists the CityData fields by JSON name, as used in CSV column
+// mappings and overlay patches
+var cityFields = []string{
+	"city", "city_ascii", "lat", "lng", "pop", "country", "iso2", "iso3",
+	"province", "timezone", "state_ansi", "exactCity", "exactProvince", "id",
+}
+
+// csvRequiredFields must be present in every CSV dataset
+var csvRequiredFields = []string{"city", "lat", "lng", "timezone"}
+
+// LoadCSV reads a city dataset from comma-separated values with a header row.
+// Coordinates and timezone names are validated for every row; the first
+// invalid row aborts the load with an error naming its line.
+func LoadCSV(r io.Reader, opts CSVOptions) (*Database, error) {
+	cities, err := readCSVCities(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewDatabase(cities), nil
+}
+
+// LoadTSV reads a city dataset from tab-separated values with a header row
+func LoadTSV(r io.Reader, opts CSVOptions) (*Database, error) {
+	opts.Comma = '\t'
+	return LoadCSV(r, opts)
+}
+
+// readCSVCities parses and validates the rows of a delimited dataset
+func readCSVCities(r io.Reader, opts CSVOptions) ([]CityData, error) {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("failed to read CSV header: empty input")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns, err := mapCSVColumns(header, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var cities []CityData
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		city, err := parseCSVRecord(record, columns)
+		if err == nil {
+			err = validateCity(city)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cities = append(cities, city)
+	}
+
+	return cities, nil
+}
+
+// mapCSVColumns resolves each CityData field to its column index in the header
+func mapCSVColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	for field := range mapping {
+		if !isCityField(field) {
+			return nil, fmt.Errorf("unknown CityData field %q in column mapping", field)
+		}
+	}
+
+	columns := make(map[string]int)
+	for _, field := range cityFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		i, ok := index[name]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q mapped to field %q not found in header", name, field)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+
+	for _, field := range csvRequiredFields {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("no column for required field %q", field)
+		}
+	}
+
+	return columns, nil
+}
+
+func isCityField(field string) bool {
+	for _, f := range cityFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCSVRecord converts one CSV row into a CityData value
+func parseCSVRecord(record []string, columns map[string]int) (CityData, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	// Optional loosely-typed fields stay nil when empty, matching the JSON data
+	optional := func(field string) interface{} {
+		if v := value(field); v != "" {
+			return v
+		}
+		return nil
+	}
+
+	city := CityData{
+		City:          value("city"),
+		CityAscii:     value("city_ascii"),
+		Country:       value("country"),
+		ISO2:          optional("iso2"),
+		ISO3:          optional("iso3"),
+		Province:      value("province"),
+		Timezone:      value("timezone"),
+		StateAnsi:     optional("state_ansi"),
+		ExactCity:     optional("exactCity"),
+		ExactProvince: optional("exactProvince"),
+		ID:            value("id"),
+	}
+
+	var err error
+	if city.Lat, err = strconv.ParseFloat(value("lat"), 64); err != nil {
+		return city, fmt.Errorf("invalid latitude %q", value("lat"))
+	}
+	if city.Lng, err = strconv.ParseFloat(value("lng"), 64); err != nil {
+		return city, fmt.Errorf("invalid longitude %q", value("lng"))
+	}
+
+	if pop := value("pop"); pop != "" {
+		// Numbers decode as float64, the same as encoding/json does for interface{}
+		n, err := strconv.ParseFloat(pop, 64)
+		if err != nil {
+			return city, fmt.Errorf("invalid population %q", pop)
+		}
+		city.Pop = n
+	}
+
+	return city, nil
+}
+
+// validateCity checks the fields every city needs to be usable for lookups
+func validateCity(c CityData) error {
+	if strings.TrimSpace(c.City) == "" {
+		return errors.New("empty city name")
+	}
+	if math.IsNaN(c.Lat) || c.Lat < -90 || c.Lat > 90 {
+		return fmt.Errorf("latitude %v out of range [-90, 90]", c.Lat)
+	}
+	if math.IsNaN(c.Lng) || c.Lng < -180 || c.Lng > 180 {
+		return fmt.Errorf("longitude %v out of range [-180, 180]", c.Lng)
+	}
+	if c.Timezone == "" {
+		return errors.New("empty timezone")
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", c.Timezone)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package citytimezones
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCampusCSV = `Site,Latitude,Longitude,Zone,Country Name,Region,Population
+North Campus,41.9000,-87.6500,America/Chicago,United States of America,Illinois,1200
+Harbor Office,47.6062,-122.3321,America/Los_Angeles,United States of America,Washington,
+`
+
+var testCampusColumns = map[string]string{
+	"city":     "Site",
+	"lat":      "Latitude",
+	"lng":      "Longitude",
+	"timezone": "Zone",
+	"country":  "Country Name",
+	"province": "Region",
+	"pop":      "Population",
+}
+
+func TestLoadCSV_ColumnMapping(t *testing.T) {
+	db, err := LoadCSV(strings.NewReader(testCampusCSV), CSVOptions{Columns: testCampusColumns})
+	if err != nil {
+		t.Fatalf("Expected CSV to load, got error: %v", err)
+	}
+
+	cities := db.LookupViaCity("north campus")
+	if len(cities) != 1 {
+		t.Fatalf("Expected 1 match for North Campus, got %d", len(cities))
+	}
+	campus := cities[0]
+	if campus.Timezone != "America/Chicago" {
+		t.Errorf("Expected timezone America/Chicago, got %s", campus.Timezone)
+	}
+	if campus.Province != "Illinois" {
+		t.Errorf("Expected province Illinois, got %s", campus.Province)
+	}
+	if pop, ok := campus.Pop.(float64); !ok || pop != 1200 {
+		t.Errorf("Expected population 1200, got %v (%T)", campus.Pop, campus.Pop)
+	}
+
+	harbor := db.LookupViaCity("Harbor Office")
+	if len(harbor) != 1 || harbor[0].Pop != nil {
+		t.Errorf("Expected Harbor Office with no population, got %v", harbor)
+	}
+}
+
+func TestLoadCSV_QueryableLikeDefaultData(t *testing.T) {
+	db, err := LoadCSV(strings.NewReader(testCampusCSV), CSVOptions{Columns: testCampusColumns})
+	if err != nil {
+		t.Fatalf("Expected CSV to load, got error: %v", err)
+	}
+
+	if got := len(db.Cities()); got != 2 {
+		t.Errorf("Expected 2 cities, got %d", got)
+	}
+	if got := db.FindNearestCities(41.8299, -87.7500, 50.0); len(got) != 1 || got[0].City != "North Campus" {
+		t.Errorf("Expected nearest search to find North Campus, got %v", got)
+	}
+	if got := db.FindFromCityStateProvince("harbor washington"); len(got) != 1 {
+		t.Errorf("Expected partial search to find Harbor Office, got %d", len(got))
+	}
+}
+
+func TestLoadTSV_DefaultColumnNames(t *testing.T) {
+	data := "city\tlat\tlng\ttimezone\tiso2\n" +
+		"Smallville\t39.7817\t-89.6501\tAmerica/Chicago\tUS\n"
+
+	db, err := LoadTSV(strings.NewReader(data), CSVOptions{})
+	if err != nil {
+		t.Fatalf("Expected TSV to load, got error: %v", err)
+	}
+	if got := db.FindFromIsoCode("us"); len(got) != 1 {
+		t.Errorf("Expected 1 city for ISO code US, got %d", len(got))
+	}
+}
+
+func TestLoadCSV_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		columns map[string]string
+		wantErr string
+	}{
+		{"latitude out of range", "city,lat,lng,timezone\nA,91,0,UTC\n", nil, "line 2: latitude"},
+		{"latitude NaN", "city,lat,lng,timezone\nA,NaN,0,UTC\n", nil, "line 2: latitude NaN"},
+		{"longitude NaN", "city,lat,lng,timezone\nA,0,nan,UTC\n", nil, "line 2: longitude NaN"},
+		{"latitude Inf", "city,lat,lng,timezone\nA,Inf,0,UTC\n", nil, "line 2: latitude +Inf"},
+		{"longitude -Inf", "city,lat,lng,timezone\nA,0,-Inf,UTC\n", nil, "line 2: longitude -Inf"},
+		{"longitude not numeric", "city,lat,lng,timezone\nA,0,east,UTC\n", nil, "line 2: invalid longitude"},
+		{"unknown timezone", "city,lat,lng,timezone\nA,0,0,Mars/Olympus\n", nil, "unknown timezone"},
+		{"empty timezone", "city,lat,lng,timezone\nA,0,0,\n", nil, "empty timezone"},
+		{"missing required column", "city,lat,lng\nA,0,0\n", nil, `required field "timezone"`},
+		{"mapped column missing", "city,lat,lng,timezone\nA,0,0,UTC\n", map[string]string{"pop": "Population"}, `column "Population"`},
+		{"unknown field", "city,lat,lng,timezone\nA,0,0,UTC\n", map[string]string{"altitude": "city"}, `unknown CityData field "altitude"`},
+		{"empty input", "", nil, "empty input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCSV(strings.NewReader(tt.data), CSVOptions{Columns: tt.columns})
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
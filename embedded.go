@@ -1,39 +1,28 @@
 package citytimezones
 
 import (
-	"bytes"
-	"compress/gzip"
-	_ "embed"
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 )
 
-// Embedded compressed city data (build-time embedded)
-//
-//go:embed data/cityMap.json.gz
-var embeddedCityData []byte
+// errNoEmbeddedData is returned when the build carries no embedded dataset
+var errNoEmbeddedData = errors.New("no embedded city data (built with citytz_noembed)")
 
-// loadEmbeddedCityData loads and decompresses the embedded city data
+// largeDataPath is the file cmd/import-geonames writes for citytz_large
+const largeDataPath = "data/large/cityMap.json.gz"
+
+// errLargeDataMissing is returned by citytz_large builds made before the
+// GeoNames import was generated
+var errLargeDataMissing = errors.New("no embedded city data: " + largeDataPath + " was not generated before building with citytz_large (run go run ./cmd/import-geonames)")
+
+// loadEmbeddedCityData loads the embedded city data, which is either the
+// binary dataset format or gzipped JSON depending on the build variant
 func loadEmbeddedCityData() ([]CityData, error) {
-	// Create gzip reader
-	gzReader, err := gzip.NewReader(bytes.NewReader(embeddedCityData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-	}
-	defer gzReader.Close()
-	
-	// Read decompressed data
-	decompressed, err := io.ReadAll(gzReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	if len(embeddedCityData) == 0 {
+		if embeddedDataset == "large" {
+			return nil, errLargeDataMissing
+		}
+		return nil, errNoEmbeddedData
 	}
-	
-	// Parse JSON
-	var cities []CityData
-	if err := json.Unmarshal(decompressed, &cities); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-	
-	return cities, nil
-}
\ No newline at end of file
+
+	return decodeDataset(embeddedCityData, "", &loadOptions{})
+}
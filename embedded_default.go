@@ -0,0 +1,20 @@
+//go:build !citytz_large && !citytz_small && !citytz_noembed
+
+package citytimezones
+
+import (
+	_ "embed"
+)
+
+// Embedded city data in the binary dataset format (build-time embedded)
+//
+//go:embed data/cityMap.bin
+var embeddedCityData []byte
+
+// embeddedDataset names the dataset variant selected by build tags
+const embeddedDataset = "default"
+
+// Metadata describing the upstream data, written by cmd/sync-data
+//
+//go:embed data/metadata.json
+var embeddedMetadata []byte
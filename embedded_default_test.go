@@ -0,0 +1,19 @@
+//go:build !citytz_large && !citytz_small && !citytz_noembed
+
+package citytimezones
+
+import "testing"
+
+func TestEmbeddedDataset_Default(t *testing.T) {
+	if embeddedDataset != "default" {
+		t.Errorf("Expected default dataset, got %s", embeddedDataset)
+	}
+
+	cities, err := loadEmbeddedCityData()
+	if err != nil {
+		t.Fatalf("Expected embedded data to load, got error: %v", err)
+	}
+	if len(cities) < 7323 {
+		t.Errorf("Expected at least 7323 embedded cities, got %d", len(cities))
+	}
+}
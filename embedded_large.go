@@ -0,0 +1,23 @@
+//go:build citytz_large && !citytz_small && !citytz_noembed
+
+package citytimezones
+
+import (
+	"embed"
+)
+
+// GeoNames-derived city data, generated by cmd/import-geonames into
+// data/large (build with -tags citytz_large). The directory is embedded
+// rather than the file so that the tag still builds before the importer has
+// run; loadEmbeddedCityData then reports errLargeDataMissing.
+//
+//go:embed data/large
+var largeDataDir embed.FS
+
+var embeddedCityData, _ = largeDataDir.ReadFile(largeDataPath)
+
+// embeddedDataset names the dataset variant selected by build tags
+const embeddedDataset = "large"
+
+// GeoNames imports carry no sync metadata
+var embeddedMetadata []byte
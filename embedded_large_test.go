@@ -0,0 +1,35 @@
+//go:build citytz_large && !citytz_small && !citytz_noembed
+
+package citytimezones
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmbeddedDataset_Large(t *testing.T) {
+	if embeddedDataset != "large" {
+		t.Errorf("Expected large dataset, got %s", embeddedDataset)
+	}
+
+	cities, err := loadEmbeddedCityData()
+	if len(embeddedCityData) == 0 {
+		// A clean checkout builds without the generated file
+		if !errors.Is(err, errLargeDataMissing) {
+			t.Errorf("Expected errLargeDataMissing without %s, got %v", largeDataPath, err)
+		}
+		t.Skipf("%s not generated, see data/large/README.md", largeDataPath)
+	}
+	if err != nil {
+		t.Fatalf("Expected the large dataset to load, got error: %v", err)
+	}
+
+	if len(cities) == 0 {
+		t.Fatal("Expected cities in the large dataset, got none")
+	}
+	for _, city := range cities {
+		if err := validateCity(city); err != nil {
+			t.Fatalf("City %s is invalid: %v", city.City, err)
+		}
+	}
+}
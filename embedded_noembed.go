@@ -0,0 +1,13 @@
+//go:build citytz_noembed
+
+package citytimezones
+
+// No data is compiled in; loadCityData falls back to the external file
+// (build with -tags citytz_noembed)
+var embeddedCityData []byte
+
+// embeddedDataset names the dataset variant selected by build tags
+const embeddedDataset = "none"
+
+// No metadata without embedded data
+var embeddedMetadata []byte
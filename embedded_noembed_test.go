@@ -0,0 +1,23 @@
+//go:build citytz_noembed
+
+package citytimezones
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmbeddedDataset_NoEmbed(t *testing.T) {
+	if embeddedDataset != "none" {
+		t.Errorf("Expected no embedded dataset, got %s", embeddedDataset)
+	}
+
+	if _, err := loadEmbeddedCityData(); !errors.Is(err, errNoEmbeddedData) {
+		t.Errorf("Expected errNoEmbeddedData, got %v", err)
+	}
+
+	// Tests run from the package directory, so the external file is found
+	if got := len(GetCityMapping()); got < 7323 {
+		t.Errorf("Expected at least 7323 cities from data/cityMap.json, got %d", got)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build citytz_small && !citytz_noembed
+
+package citytimezones
+
+import (
+	_ "embed"
+)
+
+// Embedded compressed data for cities of at least 100k inhabitants, generated
+// by cmd/sync-data (build with -tags citytz_small)
+//
+//go:embed data/cityMap.small.json.gz
+var embeddedCityData []byte
+
+// embeddedDataset names the dataset variant selected by build tags
+const embeddedDataset = "small"
+
+// Metadata describing the upstream data, written by cmd/sync-data
+//
+//go:embed data/metadata.json
+var embeddedMetadata []byte
@@ -0,0 +1,25 @@
+//go:build citytz_small && !citytz_noembed
+
+package citytimezones
+
+import "testing"
+
+func TestEmbeddedDataset_Small(t *testing.T) {
+	if embeddedDataset != "small" {
+		t.Errorf("Expected small dataset, got %s", embeddedDataset)
+	}
+
+	cities := GetCityMapping()
+	if len(cities) == 0 || len(cities) >= 7323 {
+		t.Fatalf("Expected a reduced dataset, got %d cities", len(cities))
+	}
+	for _, city := range cities {
+		if pop, ok := city.Pop.(float64); !ok || pop < 100000 {
+			t.Errorf("City %s has population %v, expected at least 100000", city.City, city.Pop)
+		}
+	}
+
+	if got := LookupViaCity("Chicago"); len(got) != 1 {
+		t.Errorf("Expected large cities like Chicago to be kept, got %d matches", len(got))
+	}
+}
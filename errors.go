@@ -0,0 +1,111 @@
+package citytimezones
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Sentinel errors returned, wrapped with detail, by the ...E lookup
+// variants. They separate invalid input from a valid query with no match,
+// which the E variants report as an empty slice and a nil error.
+var (
+	ErrEmptyQuery         = errors.New("empty query")
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+	ErrInvalidPlusCode    = errors.New("invalid plus code")
+	ErrInvalidGeohash     = errors.New("invalid geohash")
+	ErrInvalidISOCode     = errors.New("invalid ISO code")
+	ErrInvalidRadius      = errors.New("invalid radius")
+)
+
+// validateLatLng checks that a point is a real latitude and longitude
+func validateLatLng(lat, lng float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("%w: latitude %v must be between -90 and 90", ErrInvalidCoordinates, lat)
+	}
+	if math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return fmt.Errorf("%w: longitude %v must be between -180 and 180", ErrInvalidCoordinates, lng)
+	}
+	return nil
+}
+
+// nonNil returns cities, or an empty slice if it is nil
+func nonNil(cities []CityData) []CityData {
+	if cities == nil {
+		return []CityData{}
+	}
+	return cities
+}
+
+// LookupViaCityE is like LookupViaCity but returns ErrEmptyQuery for a blank
+// city name
+func LookupViaCityE(city string) ([]CityData, error) {
+	return Default().LookupViaCityE(city)
+}
+
+// LookupViaCityE finds cities in the database by exact name match, returning
+// ErrEmptyQuery for a blank city name
+func (db *Database) LookupViaCityE(city string) ([]CityData, error) {
+	if strings.TrimSpace(city) == "" {
+		return nil, fmt.Errorf("%w: city name is blank", ErrEmptyQuery)
+	}
+	return nonNil(db.LookupViaCity(city)), nil
+}
+
+// FindFromCityStateProvinceE is like FindFromCityStateProvince but returns
+// ErrEmptyQuery for a blank search string
+func FindFromCityStateProvinceE(searchString string) ([]CityData, error) {
+	return Default().FindFromCityStateProvinceE(searchString)
+}
+
+// FindFromCityStateProvinceE searches the database by city, state or
+// province, returning ErrEmptyQuery for a blank search string
+func (db *Database) FindFromCityStateProvinceE(searchString string) ([]CityData, error) {
+	if strings.TrimSpace(searchString) == "" {
+		return nil, fmt.Errorf("%w: search string is blank", ErrEmptyQuery)
+	}
+	return nonNil(db.FindFromCityStateProvince(searchString)), nil
+}
+
+// FindFromIsoCodeE is like FindFromIsoCode but returns ErrEmptyQuery for a
+// blank code and ErrInvalidISOCode for one that is not 2 or 3 letters
+func FindFromIsoCodeE(isoCode string) ([]CityData, error) {
+	return Default().FindFromIsoCodeE(isoCode)
+}
+
+// FindFromIsoCodeE finds cities in the database by ISO2 or ISO3 country code,
+// returning ErrEmptyQuery or ErrInvalidISOCode for malformed codes
+func (db *Database) FindFromIsoCodeE(isoCode string) ([]CityData, error) {
+	code := strings.TrimSpace(isoCode)
+	if code == "" {
+		return nil, fmt.Errorf("%w: ISO code is blank", ErrEmptyQuery)
+	}
+	if len(code) != 2 && len(code) != 3 {
+		return nil, fmt.Errorf("%w %q: must be 2 or 3 letters", ErrInvalidISOCode, isoCode)
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return nil, fmt.Errorf("%w %q: must be 2 or 3 letters", ErrInvalidISOCode, isoCode)
+		}
+	}
+	return nonNil(db.FindFromIsoCode(code)), nil
+}
+
+// FindNearestCitiesE is like FindNearestCities but validates its arguments
+func FindNearestCitiesE(lat, lng, radiusKm float64) ([]CityData, error) {
+	return Default().FindNearestCitiesE(lat, lng, radiusKm)
+}
+
+// FindNearestCitiesE finds cities in the database within radiusKm of a
+// point, returning ErrInvalidCoordinates for a point off the globe and
+// ErrInvalidRadius for a negative or NaN radius
+func (db *Database) FindNearestCitiesE(lat, lng, radiusKm float64) ([]CityData, error) {
+	if err := validateLatLng(lat, lng); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(radiusKm) || radiusKm < 0 {
+		return nil, fmt.Errorf("%w: %v km must not be negative", ErrInvalidRadius, radiusKm)
+	}
+	return db.FindNearestCities(lat, lng, radiusKm), nil
+}
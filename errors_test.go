@@ -0,0 +1,176 @@
+package citytimezones
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestLookupViaCityE(t *testing.T) {
+	cities, err := LookupViaCityE("Chicago")
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	cities, err = LookupViaCityE("Nonexistent City")
+	if err != nil {
+		t.Errorf("Expected no error for an unknown city, got %v", err)
+	}
+	if cities == nil || len(cities) != 0 {
+		t.Errorf("Expected an empty non-nil slice, got %v", cities)
+	}
+
+	if _, err := LookupViaCityE("   "); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Expected ErrEmptyQuery, got %v", err)
+	}
+}
+
+func TestFindFromCityStateProvinceE(t *testing.T) {
+	cities, err := FindFromCityStateProvinceE("chicago il")
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities for 'chicago il', got %d cities and error %v", len(cities), err)
+	}
+	if _, err := FindFromCityStateProvinceE(""); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Expected ErrEmptyQuery, got %v", err)
+	}
+}
+
+func TestFindFromIsoCodeE(t *testing.T) {
+	cities, err := FindFromIsoCodeE("us")
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected US cities, got %d cities and error %v", len(cities), err)
+	}
+
+	cities, err = FindFromIsoCodeE("QQ")
+	if err != nil || cities == nil || len(cities) != 0 {
+		t.Errorf("Expected an empty result for an unassigned code, got %v and error %v", cities, err)
+	}
+
+	if _, err := FindFromIsoCodeE(""); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Expected ErrEmptyQuery, got %v", err)
+	}
+	for _, code := range []string{"U", "USAX", "U1", "ÜS"} {
+		if _, err := FindFromIsoCodeE(code); !errors.Is(err, ErrInvalidISOCode) {
+			t.Errorf("%q: expected ErrInvalidISOCode, got %v", code, err)
+		}
+	}
+}
+
+func TestFindNearestCitiesE(t *testing.T) {
+	cities, err := FindNearestCitiesE(41.8299, -87.75, 50)
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities near Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	invalid := []struct {
+		lat, lng, radius float64
+		want             error
+	}{
+		{91, 0, 50, ErrInvalidCoordinates},
+		{0, -181, 50, ErrInvalidCoordinates},
+		{math.NaN(), 0, 50, ErrInvalidCoordinates},
+		{0, 0, -1, ErrInvalidRadius},
+		{0, 0, math.NaN(), ErrInvalidRadius},
+	}
+	for _, tt := range invalid {
+		if _, err := FindNearestCitiesE(tt.lat, tt.lng, tt.radius); !errors.Is(err, tt.want) {
+			t.Errorf("(%v, %v, %v): expected %v, got %v", tt.lat, tt.lng, tt.radius, tt.want, err)
+		}
+	}
+}
+
+func TestFindFromCoordinatesE_Errors(t *testing.T) {
+	for _, input := range []interface{}{"invalid", "91,0", "41.8,-87.7,0", []float64{1}, "EN6"} {
+		_, err := FindFromCoordinatesE(input)
+		if !errors.Is(err, ErrInvalidCoordinates) {
+			t.Errorf("%v: expected ErrInvalidCoordinates, got %v", input, err)
+		}
+		if errors.Is(err, ErrUnsupportedCoordinates) {
+			t.Errorf("%v: expected a parse error, not ErrUnsupportedCoordinates", input)
+		}
+	}
+
+	_, err := FindFromCoordinatesE(struct{}{})
+	if !errors.Is(err, ErrInvalidCoordinates) || !errors.Is(err, ErrUnsupportedCoordinates) {
+		t.Errorf("Expected both ErrInvalidCoordinates and ErrUnsupportedCoordinates, got %v", err)
+	}
+}
+
+func TestFindFromCoordinates_KeepsOutOfRange(t *testing.T) {
+	// Only the E variant checks the range; 272.25 is Chicago's longitude + 360
+	cities := FindFromCoordinates("41.8299,272.25")
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago for an unwrapped longitude, got %v", cities)
+	}
+	if _, err := FindFromCoordinatesE("41.8299,272.25"); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates from the E variant, got %v", err)
+	}
+}
+
+func TestFindFromPlusCodeE(t *testing.T) {
+	cities, err := FindFromPlusCodeE("86HJP27M+XF")
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities near Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	if _, err := FindFromPlusCodeE(" "); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Expected ErrEmptyQuery, got %v", err)
+	}
+	for _, code := range []string{"INVALID", "86HJP27M", "P27M+XF"} {
+		if _, err := FindFromPlusCodeE(code); !errors.Is(err, ErrInvalidPlusCode) {
+			t.Errorf("%q: expected ErrInvalidPlusCode, got %v", code, err)
+		}
+	}
+}
+
+func TestFindFromGeohashE(t *testing.T) {
+	if _, err := FindFromGeohashE("dp3wj"); err != nil {
+		t.Errorf("Expected a valid geohash to succeed, got %v", err)
+	}
+	if _, err := FindFromGeohashE(""); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("Expected ErrEmptyQuery, got %v", err)
+	}
+	if _, err := FindFromGeohashE("dp3a"); !errors.Is(err, ErrInvalidGeohash) {
+		t.Errorf("Expected ErrInvalidGeohash, got %v", err)
+	}
+}
+
+func TestFindInBoundingBoxE(t *testing.T) {
+	cities, err := FindInBoundingBoxE(41.6, -88, 42.1, -87.5)
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities around Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	invalid := [][4]float64{
+		{42.1, -88, 41.6, -87.5},
+		{-91, -88, 42.1, -87.5},
+		{41.6, math.NaN(), 42.1, -87.5},
+	}
+	for _, box := range invalid {
+		if _, err := FindInBoundingBoxE(box[0], box[1], box[2], box[3]); !errors.Is(err, ErrInvalidCoordinates) {
+			t.Errorf("%v: expected ErrInvalidCoordinates, got %v", box, err)
+		}
+	}
+}
+
+func TestFindInPolygonE(t *testing.T) {
+	ring := [][2]float64{{41.6, -88}, {41.6, -87.5}, {42.1, -87.5}, {42.1, -88}}
+	cities, err := FindInPolygonE(ring)
+	if err != nil || len(cities) == 0 {
+		t.Errorf("Expected cities around Chicago, got %d cities and error %v", len(cities), err)
+	}
+
+	if _, err := FindInPolygonE(ring[:2]); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates for a 2-point ring, got %v", err)
+	}
+	if _, err := FindInPolygonE(ring, [][2]float64{{41.8, -87.7}, {95, -87.6}, {41.9, -87.6}}); !errors.Is(err, ErrInvalidCoordinates) {
+		t.Errorf("Expected ErrInvalidCoordinates for a hole off the globe, got %v", err)
+	}
+}
+
+func TestResolveCity_EmptyQuery(t *testing.T) {
+	_, err := ResolveCity("  ")
+	if !errors.Is(err, ErrEmptyQuery) || !errors.Is(err, ErrCityNotFound) {
+		t.Errorf("Expected ErrEmptyQuery wrapped in ErrCityNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package citytimezones
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+	// MaxGeohashPrecision is the longest geohash Geohash produces, a cell of
+	// a few centimetres
+	MaxGeohashPrecision = 12
+)
+
+// GeohashCell is the area covered by a geohash
+type GeohashCell struct {
+	LatLo, LatHi float64
+	LngLo, LngHi float64
+}
+
+// Center returns the center of the cell
+func (c GeohashCell) Center() (lat, lng float64) {
+	return (c.LatLo + c.LatHi) / 2, (c.LngLo + c.LngHi) / 2
+}
+
+// EncodeGeohash returns the geohash of a point with the given number of
+// characters, clamped to 1..MaxGeohashPrecision
+func EncodeGeohash(lat, lng float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > MaxGeohashPrecision {
+		precision = MaxGeohashPrecision
+	}
+
+	latLo, latHi := -90.0, 90.0
+	lngLo, lngHi := -180.0, 180.0
+	var b strings.Builder
+	bit, ch, even := 0, 0, true
+	for b.Len() < precision {
+		// Bits alternate between longitude and latitude, longitude first
+		if even {
+			mid := (lngLo + lngHi) / 2
+			if lng >= mid {
+				ch |= 1 << (4 - bit)
+				lngLo = mid
+			} else {
+				lngHi = mid
+			}
+		} else {
+			mid := (latLo + latHi) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				latLo = mid
+			} else {
+				latHi = mid
+			}
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			b.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return b.String()
+}
+
+// DecodeGeohash returns the cell covered by a geohash (case-insensitive)
+func DecodeGeohash(hash string) (GeohashCell, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" {
+		return GeohashCell{}, errors.New("empty geohash")
+	}
+
+	cell := GeohashCell{LatLo: -90, LatHi: 90, LngLo: -180, LngHi: 180}
+	even := true
+	for i := 0; i < len(hash); i++ {
+		value := strings.IndexByte(geohashAlphabet, hash[i])
+		if value < 0 {
+			return GeohashCell{}, fmt.Errorf("invalid geohash character %q at position %d", hash[i], i)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := value&(1<<bit) != 0
+			if even {
+				mid := (cell.LngLo + cell.LngHi) / 2
+				if set {
+					cell.LngLo = mid
+				} else {
+					cell.LngHi = mid
+				}
+			} else {
+				mid := (cell.LatLo + cell.LatHi) / 2
+				if set {
+					cell.LatLo = mid
+				} else {
+					cell.LatHi = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return cell, nil
+}
+
+// Geohash returns the geohash of a city with the given number of characters.
+// Cities sharing a prefix lie in the same cell, which makes prefixes
+// convenient grouping keys.
+func Geohash(city CityData, precision int) string {
+	return EncodeGeohash(city.Lat, city.Lng, precision)
+}
+
+// FindFromGeohash finds cities near the location specified by a geohash
+func FindFromGeohash(hash string) []CityData {
+	return Default().FindFromGeohash(hash)
+}
+
+// FindFromGeohashE is like FindFromGeohash but returns ErrEmptyQuery or
+// ErrInvalidGeohash for bad hashes
+func FindFromGeohashE(hash string) ([]CityData, error) {
+	return Default().FindFromGeohashE(hash)
+}
+
+// FindFromGeohashE finds cities in the database within the cell of a
+// geohash, returning ErrInvalidGeohash with the offending character
+func (db *Database) FindFromGeohashE(hash string) ([]CityData, error) {
+	if strings.TrimSpace(hash) == "" {
+		return nil, fmt.Errorf("%w: geohash is blank", ErrEmptyQuery)
+	}
+	if _, err := DecodeGeohash(hash); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeohash, err)
+	}
+	return db.FindFromGeohash(hash), nil
+}
+
+// FindFromGeohash finds cities in the database within the cell of a
+// geohash. It searches from the cell's center with a radius of half its
+// diagonal, so short hashes cover large areas and long ones small areas.
+func (db *Database) FindFromGeohash(hash string) []CityData {
+	cell, err := DecodeGeohash(hash)
+	if err != nil {
+		return []CityData{}
+	}
+
+	lat, lng := cell.Center()
+	radius := haversineDistance(lat, lng, cell.LatHi, cell.LngHi)
+	return db.FindNearestCities(lat, lng, radius)
+}
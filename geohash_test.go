@@ -0,0 +1,98 @@
+package citytimezones
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lng  float64
+		precision int
+		want      string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{41.8781, -87.6298, 6, "dp3wjz"},
+		{-33.8688, 151.2093, 5, "r3gx2"},
+		{0, 0, 1, "s"},
+		{41.8781, -87.6298, 0, "d"},
+	}
+	for _, tt := range tests {
+		if got := EncodeGeohash(tt.lat, tt.lng, tt.precision); got != tt.want {
+			t.Errorf("EncodeGeohash(%v, %v, %d): expected %s, got %s", tt.lat, tt.lng, tt.precision, tt.want, got)
+		}
+	}
+
+	if got := EncodeGeohash(41.8781, -87.6298, 20); len(got) != MaxGeohashPrecision || got[:6] != "dp3wjz" {
+		t.Errorf("Expected a %d-character geohash starting with dp3wjz, got %s", MaxGeohashPrecision, got)
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	cell, err := DecodeGeohash("u4pruydqqvj")
+	if err != nil {
+		t.Fatalf("Expected geohash to decode, got error: %v", err)
+	}
+	lat, lng := cell.Center()
+	if math.Abs(lat-57.64911) > 1e-4 || math.Abs(lng-10.40744) > 1e-4 {
+		t.Errorf("Expected center near 57.64911,10.40744, got %v,%v", lat, lng)
+	}
+	if !(cell.LatLo <= 57.64911 && 57.64911 <= cell.LatHi && cell.LngLo <= 10.40744 && 10.40744 <= cell.LngHi) {
+		t.Errorf("Expected cell to contain the point, got %+v", cell)
+	}
+
+	upper, err := DecodeGeohash("DP3WJZ")
+	if err != nil {
+		t.Fatalf("Expected upper-case geohash to decode, got error: %v", err)
+	}
+	if lower, _ := DecodeGeohash("dp3wjz"); upper != lower {
+		t.Errorf("Expected case-insensitive decoding, got %+v and %+v", upper, lower)
+	}
+
+	for _, invalid := range []string{"", "  ", "dp3a", "u4pr!"} {
+		if _, err := DecodeGeohash(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestGeohash_RoundTrip(t *testing.T) {
+	city := CityData{City: "Chicago", Lat: 41.8781, Lng: -87.6298}
+	for precision := 1; precision <= MaxGeohashPrecision; precision++ {
+		cell, err := DecodeGeohash(Geohash(city, precision))
+		if err != nil {
+			t.Fatalf("precision %d: %v", precision, err)
+		}
+		if city.Lat < cell.LatLo || city.Lat > cell.LatHi || city.Lng < cell.LngLo || city.Lng > cell.LngHi {
+			t.Errorf("precision %d: expected cell %+v to contain the city", precision, cell)
+		}
+	}
+}
+
+func TestFindFromGeohash(t *testing.T) {
+	db := NewDatabase([]CityData{
+		{City: "Chicago", Lat: 41.8781, Lng: -87.6298, Timezone: "America/Chicago"},
+		{City: "Evanston", Lat: 42.0451, Lng: -87.6877, Timezone: "America/Chicago"},
+		{City: "Milwaukee", Lat: 43.0389, Lng: -87.9065, Timezone: "America/Chicago"},
+	})
+
+	// A 5-character cell is about 5km across: only Chicago
+	cities := db.FindFromGeohash(Geohash(db.Cities()[0], 5))
+	if len(cities) != 1 || cities[0].City != "Chicago" {
+		t.Errorf("Expected only Chicago, got %v", cityNames(cities))
+	}
+
+	// A 3-character cell is about 150km across: Chicago and Evanston
+	if cities := db.FindFromGeohash("dp3"); len(cities) != 2 {
+		t.Errorf("Expected 2 cities, got %v", cityNames(cities))
+	}
+
+	// A 2-character cell reaches Milwaukee
+	if cities := db.FindFromGeohash("dp"); len(cities) != 3 {
+		t.Errorf("Expected 3 cities, got %v", cityNames(cities))
+	}
+
+	if cities := db.FindFromGeohash("not a hash"); len(cities) != 0 {
+		t.Errorf("Expected no cities for an invalid geohash, got %v", cityNames(cities))
+	}
+}
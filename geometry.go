@@ -0,0 +1,103 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Geometry helpers shared by the boundary and region queries. Points are
+// [2]float64{x, y}; GeoJSON data is in longitude, latitude order.
+
+// polygon is an outer ring followed by any holes
+type polygon [][][2]float64
+
+// bbox is an axis-aligned bounding box
+type bbox struct {
+	minX, minY, maxX, maxY float64
+}
+
+// ringBounds returns the bounding box of a ring
+func ringBounds(ring [][2]float64) bbox {
+	b := bbox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range ring {
+		b.minX = math.Min(b.minX, p[0])
+		b.minY = math.Min(b.minY, p[1])
+		b.maxX = math.Max(b.maxX, p[0])
+		b.maxY = math.Max(b.maxY, p[1])
+	}
+	return b
+}
+
+// union extends b to cover o
+func (b bbox) union(o bbox) bbox {
+	return bbox{math.Min(b.minX, o.minX), math.Min(b.minY, o.minY), math.Max(b.maxX, o.maxX), math.Max(b.maxY, o.maxY)}
+}
+
+// contains reports whether (x, y) lies within the box, edges included
+func (b bbox) contains(x, y float64) bool {
+	return x >= b.minX && x <= b.maxX && y >= b.minY && y <= b.maxY
+}
+
+// pointInRing reports whether (x, y) is inside the ring, using the even-odd
+// rule. The ring may be closed (last point equal to the first) or not.
+func pointInRing(x, y float64, ring [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// contains reports whether (x, y) is inside the outer ring and outside every
+// hole
+func (p polygon) contains(x, y float64) bool {
+	if len(p) == 0 || !pointInRing(x, y, p[0]) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if pointInRing(x, y, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+// geoJSONGeometry is a GeoJSON Polygon or MultiPolygon geometry
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// polygons returns the polygons of a Polygon or MultiPolygon geometry
+func (g geoJSONGeometry) polygons() ([]polygon, error) {
+	switch g.Type {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		if len(p) == 0 {
+			return nil, errors.New("polygon has no rings")
+		}
+		return []polygon{p}, nil
+	case "MultiPolygon":
+		var ps []polygon
+		if err := json.Unmarshal(g.Coordinates, &ps); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+		for _, p := range ps {
+			if len(p) == 0 {
+				return nil, errors.New("polygon has no rings")
+			}
+		}
+		return ps, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q (want Polygon or MultiPolygon)", g.Type)
+	}
+}
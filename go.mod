@@ -2,4 +2,4 @@ module github.com/justcfx2u/city-timezones-go
 
 go 1.21
 
-require github.com/google/open-location-code/go v0.0.0-20250620134813-83986da0156b // indirect
+require github.com/google/open-location-code/go v0.0.0-20250620134813-83986da0156b
@@ -0,0 +1,73 @@
+package citytimezones
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CityID derives the stable identifier of a city from its country, province,
+// name and coordinates rounded to 0.1° (about 11km). The ID does not depend on
+// fields such as timezone or population, so corrections to those keep it intact.
+//
+// Cities that share all of these get a numeric suffix in dataset order
+// ("<id>-2", "<id>-3", ...) when loaded into a Database.
+func CityID(c CityData) string {
+	key := fmt.Sprintf("%s|%s|%s|%.1f|%.1f",
+		strings.ToLower(strings.TrimSpace(c.Country)),
+		strings.ToLower(strings.TrimSpace(c.Province)),
+		strings.ToLower(strings.TrimSpace(c.City)),
+		roundCoordinate(c.Lat),
+		roundCoordinate(c.Lng))
+
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// roundCoordinate rounds to one decimal place, folding -0 into 0 so that
+// cities just either side of the equator or prime meridian agree
+func roundCoordinate(v float64) float64 {
+	return math.Round(v*10)/10 + 0
+}
+
+// cityID returns the assigned ID of a city, or derives it for records that
+// have not been loaded into a Database
+func cityID(c CityData) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return CityID(c)
+}
+
+// assignIDs fills in missing IDs and indexes every city by ID, suffixing
+// duplicates so that each ID is unique within the dataset
+func assignIDs(cities []CityData, byID map[string]int) {
+	for i := range cities {
+		base := cityID(cities[i])
+		id := base
+		for n := 2; ; n++ {
+			if _, taken := byID[id]; !taken {
+				break
+			}
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		cities[i].ID = id
+		byID[id] = i
+	}
+}
+
+// GetByID finds a city by its stable identifier
+func GetByID(id string) (CityData, bool) {
+	return Default().GetByID(id)
+}
+
+// GetByID finds a city in the database by its stable identifier
+func (db *Database) GetByID(id string) (CityData, bool) {
+	i, ok := db.byID[strings.TrimSpace(id)]
+	if !ok {
+		return CityData{}, false
+	}
+	return db.cities[i], true
+}
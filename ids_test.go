@@ -0,0 +1,91 @@
+//go:build !citytz_small && !citytz_large
+
+package citytimezones
+
+import (
+	"testing"
+)
+
+func TestCityID_Deterministic(t *testing.T) {
+	chicago := LookupViaCity("Chicago")[0]
+	if chicago.ID == "" {
+		t.Fatal("Expected Chicago to have an ID")
+	}
+
+	// Recomputing from a fresh copy must give the same ID
+	fresh := chicago
+	fresh.ID = ""
+	if got := CityID(fresh); got != chicago.ID {
+		t.Errorf("Expected CityID %s, got %s", chicago.ID, got)
+	}
+
+	// Fields outside the identity do not affect the ID
+	fresh.Timezone = "UTC"
+	fresh.Pop = float64(0)
+	fresh.Lat += 0.01
+	if got := CityID(fresh); got != chicago.ID {
+		t.Errorf("Expected ID to ignore timezone, population and small coordinate changes, got %s", got)
+	}
+}
+
+func TestCityID_UniqueAcrossDataset(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, city := range GetCityMapping() {
+		if city.ID == "" {
+			t.Fatalf("City %s has no ID", city.City)
+		}
+		if seen[city.ID] {
+			t.Fatalf("Duplicate ID %s for %s", city.ID, city.City)
+		}
+		seen[city.ID] = true
+	}
+}
+
+func TestGetByID(t *testing.T) {
+	for _, springfield := range LookupViaCity("Springfield") {
+		city, ok := GetByID(springfield.ID)
+		if !ok {
+			t.Fatalf("Expected to find %s by ID %s", springfield.City, springfield.ID)
+		}
+		if city.Province != springfield.Province || city.Lat != springfield.Lat {
+			t.Errorf("Expected %s, %s for ID %s, got %s, %s",
+				springfield.City, springfield.Province, springfield.ID, city.City, city.Province)
+		}
+	}
+
+	if _, ok := GetByID("does-not-exist"); ok {
+		t.Error("Expected no city for an unknown ID")
+	}
+}
+
+func TestNewDatabase_DuplicateIDs(t *testing.T) {
+	city := CityData{City: "Twin", Country: "Nowhere", Lat: 1, Lng: 2, Timezone: "UTC"}
+	db := NewDatabase([]CityData{city, city})
+
+	cities := db.Cities()
+	if cities[0].ID == cities[1].ID {
+		t.Fatalf("Expected duplicate records to get distinct IDs, got %s twice", cities[0].ID)
+	}
+	if cities[1].ID != cities[0].ID+"-2" {
+		t.Errorf("Expected second ID %s-2, got %s", cities[0].ID, cities[1].ID)
+	}
+}
+
+func TestOverlayApply_MatchByID(t *testing.T) {
+	chicago := LookupViaCity("Chicago")[0]
+	overlay := &Overlay{Patch: []OverlayPatch{{
+		Match: CityKey{ID: chicago.ID},
+		Set:   map[string]interface{}{"pop": 1},
+	}}}
+
+	db, report, err := NewDatabase(GetCityMapping()).ApplyOverlay(overlay)
+	if err != nil {
+		t.Fatalf("Expected overlay to apply, got error: %v", err)
+	}
+	if report.Patched != 1 {
+		t.Errorf("Expected 1 patched city, got %d", report.Patched)
+	}
+	if city, _ := db.GetByID(chicago.ID); city.Pop != float64(1) {
+		t.Errorf("Expected patched population 1, got %v", city.Pop)
+	}
+}
@@ -0,0 +1,174 @@
+package citytimezones
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	// dataEnvVar names a data file that replaces the embedded dataset
+	dataEnvVar = "CITYTZ_DATA"
+
+	// externalDataPath is read when the build has no usable embedded data.
+	// It is relative to the working directory.
+	externalDataPath = "data/cityMap.json"
+)
+
+// LoadError reports which data source failed to load and why
+type LoadError struct {
+	Source string // file path, "embedded data", "reader", ...
+	Err    error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("failed to load city data from %s: %v", e.Source, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures how a dataset is loaded
+type Option func(*loadOptions)
+
+type loadOptions struct {
+	csv            *CSVOptions
+	overlay        *Overlay
+	canonicalZones bool
+}
+
+// WithCSV reads the data as CSV (or TSV, with Comma set to '\t') using the
+// given column mapping. Files named *.csv or *.tsv are read as CSV without it.
+func WithCSV(opts CSVOptions) Option {
+	return func(o *loadOptions) {
+		o.csv = &opts
+	}
+}
+
+// WithOverlay applies an overlay of local corrections to the loaded data.
+// Database.OverlayReport returns the result, including stale entries.
+func WithOverlay(overlay *Overlay) Option {
+	return func(o *loadOptions) {
+		o.overlay = overlay
+	}
+}
+
+// LoadFrom reads a dataset from r. The format is detected from the content:
+// the binary dataset format, a JSON array, or either of them gzipped. CSV
+// requires the WithCSV option.
+func LoadFrom(r io.Reader, opts ...Option) (*Database, error) {
+	return loadDatabase(r, "reader", "", opts)
+}
+
+// LoadFS reads a dataset from a file in fsys, detecting its format like
+// LoadFrom. Files named *.csv or *.tsv (optionally with .gz) are read as CSV
+// or TSV with the default column names.
+func LoadFS(fsys fs.FS, name string, opts ...Option) (*Database, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, &LoadError{Source: name, Err: err}
+	}
+	defer file.Close()
+
+	return loadDatabase(file, name, name, opts)
+}
+
+// LoadFile reads a dataset from a file on disk, detecting its format like LoadFS
+func LoadFile(filename string, opts ...Option) (*Database, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, &LoadError{Source: filename, Err: err}
+	}
+	defer file.Close()
+
+	return loadDatabase(file, filename, filename, opts)
+}
+
+func loadDatabase(r io.Reader, source, name string, opts []Option) (*Database, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &LoadError{Source: source, Err: err}
+	}
+
+	cities, err := decodeDataset(data, name, &o)
+	if err != nil {
+		return nil, &LoadError{Source: source, Err: err}
+	}
+
+	if o.canonicalZones {
+		canonicalizeZones(cities)
+	}
+
+	db := NewDatabase(cities)
+	if o.overlay != nil {
+		if db, _, err = db.ApplyOverlay(o.overlay); err != nil {
+			return nil, &LoadError{Source: source, Err: fmt.Errorf("failed to apply overlay: %w", err)}
+		}
+	}
+	return db, nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodeDataset detects the format of data and parses it. The name, if
+// known, selects CSV or TSV by extension.
+func decodeDataset(data []byte, name string, o *loadOptions) ([]CityData, error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		gzReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer gzReader.Close()
+
+		decompressed, err := io.ReadAll(gzReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress data: %w", err)
+		}
+		return decodeDataset(decompressed, strings.TrimSuffix(name, ".gz"), o)
+	}
+
+	if isBinaryDataset(data) {
+		return DecodeBinary(data)
+	}
+
+	csvOpts := o.csv
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		if csvOpts == nil {
+			csvOpts = &CSVOptions{}
+		}
+	case ".tsv":
+		if csvOpts == nil {
+			csvOpts = &CSVOptions{Comma: '\t'}
+		}
+	}
+	if csvOpts != nil {
+		return readCSVCities(bytes.NewReader(data), *csvOpts)
+	}
+
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '[' {
+		var cities []CityData
+		if err := json.Unmarshal(data, &cities); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return cities, nil
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("empty data")
+	}
+	return nil, errors.New("unrecognized data format (expected binary dataset, JSON array or gzip; use WithCSV for CSV)")
+}
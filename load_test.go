@@ -0,0 +1,181 @@
+package citytimezones
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testCitiesJSON = `[
+  {"city": "Alpha", "lat": 10, "lng": 20, "country": "Testland", "iso2": "TL", "iso3": "TST", "province": "North", "timezone": "UTC"},
+  {"city": "Beta", "lat": -10, "lng": -20, "country": "Testland", "iso2": "TL", "iso3": "TST", "province": "South", "timezone": "Europe/Paris"}
+]`
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if _, err := gzWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadFrom_DetectsFormat(t *testing.T) {
+	cities, err := decodeDataset([]byte(testCitiesJSON), "", &loadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	binaryData, err := EncodeBinary(cities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := map[string][]byte{
+		"json":        []byte(testCitiesJSON),
+		"gzip json":   gzipBytes(t, []byte(testCitiesJSON)),
+		"binary":      binaryData,
+		"gzip binary": gzipBytes(t, binaryData),
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			db, err := LoadFrom(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Expected %s data to load, got error: %v", name, err)
+			}
+			if got := db.LookupViaCity("beta"); len(got) != 1 || got[0].Timezone != "Europe/Paris" {
+				t.Errorf("Expected to find Beta in Europe/Paris, got %v", got)
+			}
+		})
+	}
+}
+
+func TestLoadFrom_CSVOption(t *testing.T) {
+	data := "name,latitude,longitude,tz\nGamma,1,2,Asia/Tokyo\n"
+	db, err := LoadFrom(strings.NewReader(data), WithCSV(CSVOptions{Columns: map[string]string{
+		"city": "name", "lat": "latitude", "lng": "longitude", "timezone": "tz",
+	}}))
+	if err != nil {
+		t.Fatalf("Expected CSV to load, got error: %v", err)
+	}
+	if got := db.LookupViaCity("Gamma"); len(got) != 1 {
+		t.Errorf("Expected to find Gamma, got %d matches", len(got))
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cities.json.gz": {Data: gzipBytes(t, []byte(testCitiesJSON))},
+		"offices.csv":    {Data: []byte("city,lat,lng,timezone\nDelta,1,2,UTC\n")},
+		"offices.tsv.gz": {Data: gzipBytes(t, []byte("city\tlat\tlng\ttimezone\nEpsilon\t1\t2\tUTC\n"))},
+	}
+
+	for name, want := range map[string]string{
+		"cities.json.gz": "Alpha",
+		"offices.csv":    "Delta",
+		"offices.tsv.gz": "Epsilon",
+	} {
+		db, err := LoadFS(fsys, name)
+		if err != nil {
+			t.Fatalf("Expected %s to load, got error: %v", name, err)
+		}
+		if got := db.LookupViaCity(want); len(got) != 1 {
+			t.Errorf("Expected to find %s in %s, got %d matches", want, name, len(got))
+		}
+	}
+}
+
+func TestLoadFS_Errors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"garbage.bin": {Data: []byte("hello world")},
+		"broken.json": {Data: []byte(`[{"city": 1}]`)},
+	}
+
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"missing.json", "failed to load city data from missing.json"},
+		{"garbage.bin", "garbage.bin: unrecognized data format"},
+		{"broken.json", "broken.json: failed to parse JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFS(fsys, tt.name)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			var loadErr *LoadError
+			if !errors.As(err, &loadErr) || loadErr.Source != tt.name {
+				t.Errorf("Expected a LoadError for source %s, got %#v", tt.name, err)
+			}
+		})
+	}
+
+	if _, err := LoadFS(fsys, "missing.json"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected missing file error to wrap fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLoadFrom_WithOverlay(t *testing.T) {
+	overlay := &Overlay{Remove: []CityKey{{City: "Alpha", Province: "North", Country: "Testland"}}}
+	db, err := LoadFrom(strings.NewReader(testCitiesJSON), WithOverlay(overlay))
+	if err != nil {
+		t.Fatalf("Expected data to load, got error: %v", err)
+	}
+	if got := len(db.Cities()); got != 1 {
+		t.Errorf("Expected 1 city after the overlay, got %d", got)
+	}
+	if report := db.OverlayReport(); report == nil || report.Removed != 1 || len(report.Stale) != 0 {
+		t.Errorf("Expected a report of 1 removal, got %+v", report)
+	}
+
+	overlay.Remove = append(overlay.Remove, CityKey{City: "Atlantis", Country: "Nowhere"})
+	db, err = LoadFrom(strings.NewReader(testCitiesJSON), WithOverlay(overlay))
+	if err != nil {
+		t.Fatalf("Expected data to load, got error: %v", err)
+	}
+	if report := db.OverlayReport(); report == nil || len(report.Stale) != 1 {
+		t.Errorf("Expected the Atlantis removal to be reported stale, got %+v", report)
+	}
+
+	db, err = LoadFrom(strings.NewReader(testCitiesJSON))
+	if err != nil {
+		t.Fatalf("Expected data to load, got error: %v", err)
+	}
+	if report := db.OverlayReport(); report != nil {
+		t.Errorf("Expected no report without an overlay, got %+v", report)
+	}
+}
+
+func TestLoadDefaultDatabase_DataEnvVar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cities.json")
+	if err := os.WriteFile(path, []byte(testCitiesJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(dataEnvVar, path)
+	db, err := loadDefaultDatabase()
+	if err != nil {
+		t.Fatalf("Expected %s to load, got error: %v", dataEnvVar, err)
+	}
+	if got := len(db.Cities()); got != 2 {
+		t.Errorf("Expected 2 cities from %s, got %d", path, got)
+	}
+
+	// A broken override must fail rather than fall back to embedded data
+	t.Setenv(dataEnvVar, filepath.Join(t.TempDir(), "missing.json"))
+	_, err = loadDefaultDatabase()
+	if err == nil || !strings.Contains(err.Error(), dataEnvVar) || !strings.Contains(err.Error(), "missing.json") {
+		t.Errorf("Expected an error naming %s and the missing file, got %v", dataEnvVar, err)
+	}
+}
@@ -0,0 +1,140 @@
+package citytimezones
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Window is an interval during which the listed cities are all inside their
+// local working hours
+type Window struct {
+	Start  time.Time // UTC
+	End    time.Time // UTC
+	Cities []CityData
+	Full   bool // every requested city is available
+}
+
+// Duration returns the length of the window
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// FindMeetingWindows returns the intervals on date during which every city is
+// inside local working hours, workStart to workEnd after local midnight (for
+// example 9*time.Hour and 17*time.Hour). Working hours are wall-clock times
+// in each city's timezone, so days with a DST transition are handled.
+//
+// The date is the calendar day of date in its own location; windows are
+// clipped to that day, and a city's working hours on the neighbouring local
+// days count as well, so cities on both sides of the date line can meet.
+//
+// If no full overlap exists, the partial windows are returned instead,
+// ranked by the number of cities available, then by duration. Cities whose
+// timezone cannot be loaded are never available.
+func FindMeetingWindows(cities []CityData, date time.Time, workStart, workEnd time.Duration) []Window {
+	if len(cities) == 0 || workEnd <= workStart {
+		return nil
+	}
+
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	dayEnd := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, date.Location())
+
+	type event struct {
+		at    time.Time
+		city  int
+		delta int
+	}
+	var events []event
+	for i, c := range cities {
+		loc, err := cityLocation(c)
+		if err != nil {
+			continue
+		}
+		for offset := -1; offset <= 1; offset++ {
+			start := workingTime(date, offset, workStart, loc)
+			end := workingTime(date, offset, workEnd, loc)
+			if start.Before(dayStart) {
+				start = dayStart
+			}
+			if end.After(dayEnd) {
+				end = dayEnd
+			}
+			if !start.Before(end) {
+				continue
+			}
+			events = append(events, event{start, i, 1}, event{end, i, -1})
+		}
+	}
+
+	// Ends sort before starts at the same instant, so back-to-back intervals
+	// do not produce zero-length windows
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	var windows []Window
+	var lastMembers []int
+	active := make([]int, len(cities))
+	for i, e := range events {
+		active[e.city] += e.delta
+		if i+1 == len(events) || !events[i+1].at.After(e.at) {
+			continue
+		}
+
+		var members []int
+		var available []CityData
+		for j, n := range active {
+			if n > 0 {
+				members = append(members, j)
+				available = append(available, cities[j])
+			}
+		}
+		if len(available) == 0 {
+			continue
+		}
+
+		window := Window{
+			Start:  e.at.UTC(),
+			End:    events[i+1].at.UTC(),
+			Cities: available,
+			Full:   len(available) == len(cities),
+		}
+		// Merge with the previous window if the same cities continue
+		if n := len(windows); n > 0 && windows[n-1].End.Equal(window.Start) && slices.Equal(lastMembers, members) {
+			windows[n-1].End = window.End
+			continue
+		}
+		windows = append(windows, window)
+		lastMembers = members
+	}
+
+	var full []Window
+	for _, w := range windows {
+		if w.Full {
+			full = append(full, w)
+		}
+	}
+	if len(full) > 0 {
+		return full
+	}
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		if len(windows[i].Cities) != len(windows[j].Cities) {
+			return len(windows[i].Cities) > len(windows[j].Cities)
+		}
+		return windows[i].Duration() > windows[j].Duration()
+	})
+	return windows
+}
+
+// workingTime returns the instant at which the wall clock in loc reads d after
+// midnight, offset days from date's calendar day. time.Date normalizes the
+// seconds as wall-clock time, so DST gaps and repeats are resolved the same
+// way as any other local time.
+func workingTime(date time.Time, offset int, d time.Duration, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day()+offset, 0, 0, int(d/time.Second), int(d%time.Second), loc)
+}
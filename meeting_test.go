@@ -0,0 +1,103 @@
+package citytimezones
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	testChicago = CityData{City: "Chicago", Timezone: "America/Chicago"}
+	testLondon  = CityData{City: "London", Timezone: "Europe/London"}
+	testTokyo   = CityData{City: "Tokyo", Timezone: "Asia/Tokyo"}
+	testSydney  = CityData{City: "Sydney", Timezone: "Australia/Sydney"}
+)
+
+func TestFindMeetingWindows_FullOverlap(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// Chicago 9-17 is 15:00-23:00 UTC, London 9-17 is 09:00-17:00 UTC
+	windows := FindMeetingWindows([]CityData{testChicago, testLondon}, date, 9*time.Hour, 17*time.Hour)
+	if len(windows) != 1 {
+		t.Fatalf("Expected 1 window, got %d", len(windows))
+	}
+	w := windows[0]
+	if !w.Full || len(w.Cities) != 2 {
+		t.Errorf("Expected a full window with 2 cities, got %+v", w)
+	}
+	if w.Start != time.Date(2024, 1, 15, 15, 0, 0, 0, time.UTC) || w.End != time.Date(2024, 1, 15, 17, 0, 0, 0, time.UTC) {
+		t.Errorf("Expected 15:00-17:00 UTC, got %s-%s", w.Start, w.End)
+	}
+}
+
+func TestFindMeetingWindows_DSTMismatch(t *testing.T) {
+	// On 2024-03-15 Chicago is already on CDT (UTC-5) but London is still
+	// on GMT, so the overlap grows to three hours
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	windows := FindMeetingWindows([]CityData{testChicago, testLondon}, date, 9*time.Hour, 17*time.Hour)
+	if len(windows) != 1 {
+		t.Fatalf("Expected 1 window, got %d", len(windows))
+	}
+	if windows[0].Duration() != 3*time.Hour {
+		t.Errorf("Expected 3h overlap, got %s", windows[0].Duration())
+	}
+}
+
+func TestFindMeetingWindows_TransitionDay(t *testing.T) {
+	// Chicago springs forward at 02:00 on 2024-03-10; 9:00 CDT is 14:00 UTC
+	date := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	windows := FindMeetingWindows([]CityData{testChicago}, date, 9*time.Hour, 17*time.Hour)
+	if len(windows) != 1 {
+		t.Fatalf("Expected 1 window, got %d", len(windows))
+	}
+	if windows[0].Start.Hour() != 14 {
+		t.Errorf("Expected start at 14:00 UTC, got %s", windows[0].Start)
+	}
+}
+
+func TestFindMeetingWindows_PartialRanked(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// Chicago and Tokyo working hours never overlap
+	windows := FindMeetingWindows([]CityData{testChicago, testLondon, testTokyo}, date, 9*time.Hour, 17*time.Hour)
+	if len(windows) == 0 {
+		t.Fatal("Expected partial windows")
+	}
+	for i, w := range windows {
+		if w.Full {
+			t.Errorf("Expected no full windows, got %+v", w)
+		}
+		if i > 0 && len(w.Cities) > len(windows[i-1].Cities) {
+			t.Errorf("Expected windows ranked by coverage, got %d after %d cities", len(w.Cities), len(windows[i-1].Cities))
+		}
+	}
+	if len(windows[0].Cities) != 2 {
+		t.Errorf("Expected best window to cover 2 cities, got %d", len(windows[0].Cities))
+	}
+}
+
+func TestFindMeetingWindows_DateLine(t *testing.T) {
+	// Sydney (UTC+11) 9-17 on the 16th local time is 22:00-06:00 UTC,
+	// which overlaps Chicago's afternoon on the 15th
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	windows := FindMeetingWindows([]CityData{testChicago, testSydney}, date, 8*time.Hour, 18*time.Hour)
+	if len(windows) != 1 || !windows[0].Full {
+		t.Fatalf("Expected 1 full window, got %+v", windows)
+	}
+	if windows[0].Start.Hour() != 21 || windows[0].End.Hour() != 0 {
+		t.Errorf("Expected 21:00-00:00 UTC, got %s-%s", windows[0].Start, windows[0].End)
+	}
+}
+
+func TestFindMeetingWindows_Invalid(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	if windows := FindMeetingWindows(nil, date, 9*time.Hour, 17*time.Hour); windows != nil {
+		t.Errorf("Expected nil for no cities, got %v", windows)
+	}
+	if windows := FindMeetingWindows([]CityData{testLondon}, date, 17*time.Hour, 9*time.Hour); windows != nil {
+		t.Errorf("Expected nil for inverted hours, got %v", windows)
+	}
+}
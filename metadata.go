@@ -0,0 +1,49 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DatasetMetadata describes the upstream data a dataset was synced from, as
+// recorded in data/metadata.json by cmd/sync-data
+type DatasetMetadata struct {
+	Source   string    `json:"source"`             // upstream URL or local file
+	Revision string    `json:"revision,omitempty"` // upstream commit or tag, if known
+	SHA256   string    `json:"sha256"`             // digest of the upstream file
+	Records  int       `json:"records"`            // cities written by the sync
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// DatasetDetails identifies the data compiled into the binary and the data
+// currently in use
+type DatasetDetails struct {
+	DatasetMetadata
+
+	// Dataset is the variant selected by build tags: "default", "small",
+	// "large" or "none"
+	Dataset string `json:"dataset"`
+
+	// Cities is the number of cities in the default database, which differs
+	// from Records when CITYTZ_DATA, an overlay or Reload replaced the data
+	Cities int `json:"cities"`
+
+	// Generation counts the datasets installed as the default database
+	Generation uint64 `json:"generation"`
+}
+
+// DatasetInfo returns the metadata of the embedded dataset together with the
+// size of the default database. The metadata fields are empty for builds
+// without sync metadata (citytz_large, citytz_noembed).
+func DatasetInfo() DatasetDetails {
+	info := DatasetDetails{
+		Dataset:    embeddedDataset,
+		Cities:     len(Default().cities),
+		Generation: Generation(),
+	}
+	if len(embeddedMetadata) > 0 {
+		// Written by cmd/sync-data; a malformed file leaves the fields empty
+		_ = json.Unmarshal(embeddedMetadata, &info.DatasetMetadata)
+	}
+	return info
+}
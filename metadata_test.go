@@ -0,0 +1,53 @@
+//go:build !citytz_large && !citytz_noembed
+
+package citytimezones
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDatasetInfo_Embedded(t *testing.T) {
+	info := DatasetInfo()
+
+	if info.Dataset != embeddedDataset {
+		t.Errorf("Expected dataset %s, got %s", embeddedDataset, info.Dataset)
+	}
+	if len(info.SHA256) != 64 {
+		t.Errorf("Expected a hex SHA-256, got %q", info.SHA256)
+	}
+	if info.Source == "" {
+		t.Error("Expected a source")
+	}
+	if info.Records == 0 {
+		t.Error("Expected a record count")
+	}
+	if info.SyncedAt.IsZero() {
+		t.Error("Expected a sync time")
+	}
+	if info.Cities != len(Default().Cities()) {
+		t.Errorf("Expected %d cities, got %d", len(Default().Cities()), info.Cities)
+	}
+	if info.Generation != Generation() {
+		t.Errorf("Expected generation %d, got %d", Generation(), info.Generation)
+	}
+}
+
+func TestDatasetInfo_AfterReload(t *testing.T) {
+	restoreDefault(t)
+
+	path := filepath.Join(t.TempDir(), "cities.json")
+	writeTestCities(t, path, testCitiesJSON)
+
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+
+	info := DatasetInfo()
+	if info.Cities != 2 {
+		t.Errorf("Expected 2 cities after reload, got %d", info.Cities)
+	}
+	if info.Records == info.Cities {
+		t.Error("Expected embedded record count to be kept after reload")
+	}
+}
@@ -0,0 +1,259 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// overlayEnvVar names an overlay file applied to the default data at load time
+const overlayEnvVar = "CITYTZ_OVERLAY"
+
+// CityKey identifies a city, either by its stable ID or by name, province
+// and country. Name matching is case-insensitive and every field must match,
+// so an empty Province only matches cities without a province.
+type CityKey struct {
+	ID       string `json:"id,omitempty"`
+	City     string `json:"city,omitempty"`
+	Province string `json:"province,omitempty"`
+	Country  string `json:"country,omitempty"`
+}
+
+// KeyOf returns the name-based identity key of a city
+func KeyOf(c CityData) CityKey {
+	return CityKey{City: c.City, Province: c.Province, Country: c.Country}
+}
+
+// Matches reports whether the key identifies the given city
+func (k CityKey) Matches(c CityData) bool {
+	if k.ID != "" {
+		return strings.TrimSpace(k.ID) == cityID(c)
+	}
+	return strings.EqualFold(strings.TrimSpace(k.City), c.City) &&
+		strings.EqualFold(strings.TrimSpace(k.Province), c.Province) &&
+		strings.EqualFold(strings.TrimSpace(k.Country), c.Country)
+}
+
+// String formats the key as "city, province, country", or "id:<id>"
+func (k CityKey) String() string {
+	if k.ID != "" {
+		return "id:" + k.ID
+	}
+	parts := []string{k.City}
+	if k.Province != "" {
+		parts = append(parts, k.Province)
+	}
+	parts = append(parts, k.Country)
+	return strings.Join(parts, ", ")
+}
+
+func (k CityKey) valid() bool {
+	return k.ID != "" || (k.City != "" && k.Country != "")
+}
+
+// Overlay is a set of local corrections applied on top of the upstream data.
+// Removals are applied first, then patches, then additions.
+type Overlay struct {
+	Add    []CityData     `json:"add,omitempty"`
+	Remove []CityKey      `json:"remove,omitempty"`
+	Patch  []OverlayPatch `json:"patch,omitempty"`
+}
+
+// OverlayPatch replaces fields of the cities matching a key. Set maps
+// CityData JSON field names ("timezone", "iso2", ...) to their new values.
+type OverlayPatch struct {
+	Match CityKey                `json:"match"`
+	Set   map[string]interface{} `json:"set"`
+}
+
+// OverlayIssue describes an overlay entry that did not apply cleanly
+type OverlayIssue struct {
+	Op     string // "add", "remove" or "patch"
+	Key    CityKey
+	Reason string
+}
+
+// String formats the issue for reports
+func (i OverlayIssue) String() string {
+	return fmt.Sprintf("%s %q: %s", i.Op, i.Key.String(), i.Reason)
+}
+
+// OverlayReport summarizes the result of applying an overlay
+type OverlayReport struct {
+	Added   int
+	Removed int
+	Patched int
+
+	// Stale lists entries that no longer match the data, typically because
+	// upstream fixed, renamed or dropped the city they refer to
+	Stale []OverlayIssue
+}
+
+// ParseOverlay reads an overlay from JSON
+func ParseOverlay(r io.Reader) (*Overlay, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var overlay Overlay
+	if err := decoder.Decode(&overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay: %w", err)
+	}
+
+	for i, key := range overlay.Remove {
+		if !key.valid() {
+			return nil, fmt.Errorf("remove %d: key needs an id, or a city and country", i)
+		}
+	}
+	for i, patch := range overlay.Patch {
+		if !patch.Match.valid() {
+			return nil, fmt.Errorf("patch %d: match needs an id, or a city and country", i)
+		}
+		if len(patch.Set) == 0 {
+			return nil, fmt.Errorf("patch %d (%s): nothing to set", i, patch.Match)
+		}
+		for field := range patch.Set {
+			if !isCityField(field) {
+				return nil, fmt.Errorf("patch %d (%s): unknown CityData field %q", i, patch.Match, field)
+			}
+		}
+	}
+
+	return &overlay, nil
+}
+
+// LoadOverlayFile reads an overlay from a JSON file
+func LoadOverlayFile(path string) (*Overlay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open overlay: %w", err)
+	}
+	defer file.Close()
+
+	overlay, err := ParseOverlay(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return overlay, nil
+}
+
+// Apply returns a copy of cities with the overlay applied. Entries that
+// match nothing are recorded in the report rather than treated as errors;
+// an error is returned only when an added or patched city is invalid.
+func (o *Overlay) Apply(cities []CityData) ([]CityData, *OverlayReport, error) {
+	report := &OverlayReport{}
+
+	removed := make([]bool, len(cities))
+	for _, key := range o.Remove {
+		matched := false
+		for i, c := range cities {
+			if !removed[i] && key.Matches(c) {
+				removed[i] = true
+				matched = true
+				report.Removed++
+			}
+		}
+		if !matched {
+			report.Stale = append(report.Stale, OverlayIssue{Op: "remove", Key: key, Reason: "no matching city"})
+		}
+	}
+
+	result := make([]CityData, 0, len(cities)+len(o.Add))
+	for i, c := range cities {
+		if !removed[i] {
+			result = append(result, c)
+		}
+	}
+
+	for _, patch := range o.Patch {
+		matched := false
+		for i, c := range result {
+			if !patch.Match.Matches(c) {
+				continue
+			}
+			patched, err := patchCity(c, patch.Set)
+			if err != nil {
+				return nil, nil, fmt.Errorf("patch %s: %w", patch.Match, err)
+			}
+			result[i] = patched
+			matched = true
+			report.Patched++
+		}
+		if !matched {
+			report.Stale = append(report.Stale, OverlayIssue{Op: "patch", Key: patch.Match, Reason: "no matching city"})
+		}
+	}
+
+	for _, c := range o.Add {
+		if err := validateCity(c); err != nil {
+			return nil, nil, fmt.Errorf("add %s: %w", KeyOf(c), err)
+		}
+		// An addition that upstream has since picked up replaces the upstream record
+		key := KeyOf(c)
+		replaced := false
+		for i, existing := range result {
+			if key.Matches(existing) {
+				result[i] = c
+				replaced = true
+				report.Stale = append(report.Stale, OverlayIssue{Op: "add", Key: key, Reason: "city already present"})
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, c)
+		}
+		report.Added++
+	}
+
+	return result, report, nil
+}
+
+// patchCity overwrites the given JSON fields of a city
+func patchCity(c CityData, set map[string]interface{}) (CityData, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return c, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return c, err
+	}
+	for field, value := range set {
+		fields[field] = value
+	}
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return c, err
+	}
+
+	var patched CityData
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return c, fmt.Errorf("invalid value: %w", err)
+	}
+	if err := validateCity(patched); err != nil {
+		return c, err
+	}
+	return patched, nil
+}
+
+// ApplyOverlay returns a new database with the overlay applied. The new
+// database also keeps the report, see OverlayReport.
+func (db *Database) ApplyOverlay(o *Overlay) (*Database, *OverlayReport, error) {
+	cities, report, err := o.Apply(db.cities)
+	if err != nil {
+		return nil, nil, err
+	}
+	applied := NewDatabase(cities)
+	applied.overlayReport = report
+	return applied, report, nil
+}
+
+// OverlayReport returns the report of the overlay the database was built
+// with, by ApplyOverlay or the WithOverlay load option, or nil if none was
+// applied
+func (db *Database) OverlayReport() *OverlayReport {
+	return db.overlayReport
+}
@@ -0,0 +1,134 @@
+//go:build !citytz_small && !citytz_large
+
+package citytimezones
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOverlay = `{
+  "add": [
+    {"city": "Smallville", "lat": 39.78, "lng": -89.65, "country": "United States of America",
+     "iso2": "US", "iso3": "USA", "province": "Illinois", "timezone": "America/Chicago"}
+  ],
+  "remove": [
+    {"city": "Atlantis", "country": "Nowhere"}
+  ],
+  "patch": [
+    {"match": {"city": "Pristina", "province": "Pristina", "country": "Kosovo"}, "set": {"iso2": "XK"}}
+  ]
+}`
+
+func TestOverlayApply(t *testing.T) {
+	overlay, err := ParseOverlay(strings.NewReader(testOverlay))
+	if err != nil {
+		t.Fatalf("Expected overlay to parse, got error: %v", err)
+	}
+
+	db, report, err := NewDatabase(GetCityMapping()).ApplyOverlay(overlay)
+	if err != nil {
+		t.Fatalf("Expected overlay to apply, got error: %v", err)
+	}
+
+	if report.Added != 1 || report.Patched != 1 || report.Removed != 0 {
+		t.Errorf("Expected 1 added, 1 patched, 0 removed, got %+v", report)
+	}
+	if len(report.Stale) != 1 || report.Stale[0].Op != "remove" {
+		t.Errorf("Expected the Atlantis removal to be reported stale, got %v", report.Stale)
+	}
+
+	if got := db.LookupViaCity("Smallville"); len(got) != 1 {
+		t.Errorf("Expected Smallville to be added, got %d matches", len(got))
+	}
+	if got := db.FindFromIsoCode("XK"); len(got) != 1 || got[0].City != "Pristina" {
+		t.Errorf("Expected Pristina to be patched to ISO2 XK, got %v", got)
+	}
+
+	// The source data must not be modified
+	if got := FindFromIsoCode("XK"); len(got) != 0 {
+		t.Errorf("Expected default data to be unchanged, got %d cities for XK", len(got))
+	}
+}
+
+func TestOverlayApply_RemoveAndReAdd(t *testing.T) {
+	chicago := LookupViaCity("Chicago")[0]
+	overlay := &Overlay{
+		Remove: []CityKey{KeyOf(chicago)},
+	}
+
+	cities, report, err := overlay.Apply(GetCityMapping())
+	if err != nil {
+		t.Fatalf("Expected overlay to apply, got error: %v", err)
+	}
+	if report.Removed != 1 || len(cities) != len(GetCityMapping())-1 {
+		t.Errorf("Expected Chicago to be removed, got report %+v", report)
+	}
+
+	// Adding a city that is already present replaces it and flags the entry
+	chicago.Pop = float64(1)
+	overlay = &Overlay{Add: []CityData{chicago}}
+	cities, report, err = overlay.Apply(GetCityMapping())
+	if err != nil {
+		t.Fatalf("Expected overlay to apply, got error: %v", err)
+	}
+	if len(cities) != len(GetCityMapping()) {
+		t.Errorf("Expected no duplicate for an existing city, got %d cities", len(cities))
+	}
+	if len(report.Stale) != 1 || report.Stale[0].Reason != "city already present" {
+		t.Errorf("Expected the redundant addition to be reported, got %v", report.Stale)
+	}
+}
+
+func TestParseOverlay_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unknown operation", `{"rename": []}`, "unknown field"},
+		{"unknown patch field", `{"patch": [{"match": {"city": "A", "country": "B"}, "set": {"altitude": 1}}]}`, `unknown CityData field "altitude"`},
+		{"empty patch", `{"patch": [{"match": {"city": "A", "country": "B"}}]}`, "nothing to set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOverlay(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOverlayApply_InvalidPatch(t *testing.T) {
+	overlay := &Overlay{Patch: []OverlayPatch{{
+		Match: KeyOf(LookupViaCity("Chicago")[0]),
+		Set:   map[string]interface{}{"timezone": "America/Chicgo"},
+	}}}
+
+	if _, _, err := overlay.Apply(GetCityMapping()); err == nil {
+		t.Error("Expected an error for a patch with an unknown timezone")
+	}
+}
+
+func TestLoadOverlayFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(path, []byte(testOverlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	overlay, err := LoadOverlayFile(path)
+	if err != nil {
+		t.Fatalf("Expected overlay file to load, got error: %v", err)
+	}
+	if len(overlay.Add) != 1 || len(overlay.Remove) != 1 || len(overlay.Patch) != 1 {
+		t.Errorf("Expected 1 entry per operation, got %+v", overlay)
+	}
+
+	if _, err := LoadOverlayFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing overlay file")
+	}
+}
@@ -0,0 +1,152 @@
+package citytimezones
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/open-location-code/go"
+)
+
+// FindFromPlusCodeE is like FindFromPlusCode but returns ErrEmptyQuery or
+// ErrInvalidPlusCode instead of an empty slice for bad codes
+func FindFromPlusCodeE(plusCode string) ([]CityData, error) {
+	return Default().FindFromPlusCodeE(plusCode)
+}
+
+// FindFromPlusCodeE finds cities in the database near a Plus Code. A full
+// code may be followed by a locality, which is ignored; a short code must
+// be, and is recovered with FindFromShortPlusCodeE. Malformed codes return
+// ErrInvalidPlusCode with the decoder's reason.
+func (db *Database) FindFromPlusCodeE(plusCode string) ([]CityData, error) {
+	if strings.TrimSpace(plusCode) == "" {
+		return nil, fmt.Errorf("%w: plus code is blank", ErrEmptyQuery)
+	}
+
+	code, locality := SplitPlusCode(plusCode)
+	if olc.CheckShort(code) == nil {
+		if locality == "" {
+			return nil, fmt.Errorf("%w %q: short codes need a locality, e.g. %q", ErrInvalidPlusCode, plusCode, code+" Chicago")
+		}
+		return db.FindFromShortPlusCodeE(code, locality)
+	}
+	return db.nearPlusCode(code)
+}
+
+// nearPlusCode returns the cities within 50km of the center of a full code
+func (db *Database) nearPlusCode(code string) ([]CityData, error) {
+	area, err := olc.Decode(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidPlusCode, code, err)
+	}
+	lat, lng := area.Center()
+	return db.FindNearestCities(lat, lng, 50.0), nil
+}
+
+// SplitPlusCode splits a Plus Code address such as "P27M+XF Chicago,
+// Illinois" into the code and the locality around it. Strings without a
+// token containing "+" are returned whole as the code.
+func SplitPlusCode(s string) (code, locality string) {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		if strings.Contains(f, string(olc.Separator)) {
+			rest := append(append([]string(nil), fields[:i]...), fields[i+1:]...)
+			return f, strings.Join(rest, " ")
+		}
+	}
+	return strings.TrimSpace(s), ""
+}
+
+// FindFromShortPlusCode finds cities near a short Plus Code, such as
+// "P27M+XF", recovered relative to a locality such as "Chicago"
+func FindFromShortPlusCode(code, locality string) []CityData {
+	return Default().FindFromShortPlusCode(code, locality)
+}
+
+// FindFromShortPlusCode finds cities in the database near a short Plus Code
+// recovered relative to a locality
+func (db *Database) FindFromShortPlusCode(code, locality string) []CityData {
+	cities, err := db.FindFromShortPlusCodeE(code, locality)
+	if err != nil {
+		return []CityData{}
+	}
+	return cities
+}
+
+// FindFromShortPlusCodeE is like FindFromShortPlusCode but returns the reason
+// a code could not be recovered
+func FindFromShortPlusCodeE(code, locality string) ([]CityData, error) {
+	return Default().FindFromShortPlusCodeE(code, locality)
+}
+
+// FindFromShortPlusCodeE finds cities in the database near a short Plus Code
+// recovered with RecoverPlusCode
+func (db *Database) FindFromShortPlusCodeE(code, locality string) ([]CityData, error) {
+	full, err := db.RecoverPlusCode(code, locality)
+	if err != nil {
+		return nil, err
+	}
+	return db.nearPlusCode(full)
+}
+
+// RecoverPlusCode returns the full Plus Code of a short code near a locality
+func RecoverPlusCode(code, locality string) (string, error) {
+	return Default().RecoverPlusCode(code, locality)
+}
+
+// RecoverPlusCode returns the full Plus Code of a short code near a locality
+// in the database. The locality is resolved like ResolveCity's query, with
+// commas ignored; trailing comma-separated parts that match nothing, such as
+// a country spelled differently, are dropped. A full code is returned as is.
+//
+// Errors wrap ErrEmptyQuery for a blank code or locality, ErrInvalidPlusCode
+// for a malformed code, and ErrCityNotFound for an unknown locality. A
+// locality naming cities far enough apart to recover different codes
+// ("Springfield") yields an *AmbiguousCityError.
+func (db *Database) RecoverPlusCode(code, locality string) (string, error) {
+	code = strings.TrimSpace(code)
+	switch {
+	case code == "":
+		return "", fmt.Errorf("%w: plus code is blank", ErrEmptyQuery)
+	case olc.CheckFull(code) == nil:
+		return strings.ToUpper(code), nil
+	case olc.CheckShort(code) != nil:
+		return "", fmt.Errorf("%w %q: not a full or short code", ErrInvalidPlusCode, code)
+	case strings.TrimSpace(locality) == "":
+		return "", fmt.Errorf("%w: locality is blank for short plus code %q", ErrEmptyQuery, code)
+	}
+
+	candidates := db.localityCandidates(locality)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w: locality %q", ErrCityNotFound, locality)
+	}
+
+	var recovered string
+	for _, c := range candidates {
+		full, err := olc.RecoverNearest(code, c.Lat, c.Lng)
+		if err != nil {
+			return "", fmt.Errorf("%w %q: %v", ErrInvalidPlusCode, code, err)
+		}
+		if recovered != "" && full != recovered {
+			return "", &AmbiguousCityError{Query: locality, Candidates: candidates}
+		}
+		recovered = full
+	}
+	return recovered, nil
+}
+
+// localityCandidates returns the cities a locality such as "Chicago, IL,
+// USA" names, dropping trailing comma-separated parts until one matches
+func (db *Database) localityCandidates(locality string) []CityData {
+	var parts []string
+	for _, p := range strings.Split(locality, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	for n := len(parts); n > 0; n-- {
+		if candidates := db.cityCandidates(strings.Join(parts[:n], " ")); len(candidates) > 0 {
+			return candidates
+		}
+	}
+	return nil
+}
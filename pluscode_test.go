@@ -0,0 +1,102 @@
+//go:build !citytz_small && !citytz_large
+
+package citytimezones
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplitPlusCode(t *testing.T) {
+	tests := []struct {
+		input, code, locality string
+	}{
+		{"P27M+XF Chicago, Illinois", "P27M+XF", "Chicago, Illinois"},
+		{"  P27M+XF   Chicago ", "P27M+XF", "Chicago"},
+		{"Chicago P27M+XF", "P27M+XF", "Chicago"},
+		{"86HJP27M+XF", "86HJP27M+XF", ""},
+		{"invalid", "invalid", ""},
+	}
+	for _, tt := range tests {
+		code, locality := SplitPlusCode(tt.input)
+		if code != tt.code || locality != tt.locality {
+			t.Errorf("%q: expected (%q, %q), got (%q, %q)", tt.input, tt.code, tt.locality, code, locality)
+		}
+	}
+}
+
+func TestRecoverPlusCode(t *testing.T) {
+	for _, locality := range []string{"Chicago", "chicago, illinois", "Chicago, IL, USA"} {
+		full, err := RecoverPlusCode("P27M+XF", locality)
+		if err != nil || full != "86HJP27M+XF" {
+			t.Errorf("%q: expected 86HJP27M+XF, got %q and error %v", locality, full, err)
+		}
+	}
+
+	// Full codes are returned without a locality
+	if full, err := RecoverPlusCode("86hjp27m+xf", ""); err != nil || full != "86HJP27M+XF" {
+		t.Errorf("Expected full code unchanged, got %q and error %v", full, err)
+	}
+}
+
+func TestRecoverPlusCode_Errors(t *testing.T) {
+	tests := []struct {
+		code, locality string
+		want           error
+	}{
+		{"", "Chicago", ErrEmptyQuery},
+		{"P27M+XF", " ", ErrEmptyQuery},
+		{"P27M+X", "Chicago", ErrInvalidPlusCode},
+		{"P27M+XF", "Nonexistent City", ErrCityNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := RecoverPlusCode(tt.code, tt.locality); !errors.Is(err, tt.want) {
+			t.Errorf("(%q, %q): expected %v, got %v", tt.code, tt.locality, tt.want, err)
+		}
+	}
+}
+
+func TestRecoverPlusCode_Ambiguous(t *testing.T) {
+	_, err := RecoverPlusCode("P27M+XF", "Springfield")
+	var ambiguous *AmbiguousCityError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected *AmbiguousCityError for Springfield, got %v", err)
+	}
+	if len(ambiguous.Candidates) < 2 {
+		t.Errorf("Expected several candidates, got %d", len(ambiguous.Candidates))
+	}
+
+	// Narrowing the locality resolves it
+	if _, err := RecoverPlusCode("P27M+XF", "Springfield, Missouri"); err != nil {
+		t.Errorf("Expected Springfield, Missouri to resolve, got %v", err)
+	}
+}
+
+func TestFindFromShortPlusCode(t *testing.T) {
+	cities := FindFromShortPlusCode("P27M+XF", "Chicago")
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago first, got %v", cityNames(cities))
+	}
+
+	cities = FindFromShortPlusCode("P27M+XF", "Nonexistent City")
+	if cities == nil || len(cities) != 0 {
+		t.Errorf("Expected an empty non-nil slice, got %v", cities)
+	}
+}
+
+func TestFindFromPlusCode_ShortWithLocality(t *testing.T) {
+	cities := FindFromPlusCode("P27M+XF Chicago, Illinois")
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago first, got %v", cityNames(cities))
+	}
+
+	// A locality after a full code is ignored
+	cities = FindFromPlusCode("86HJP27M+XF Chicago")
+	if len(cities) == 0 || cities[0].City != "Chicago" {
+		t.Errorf("Expected Chicago first, got %v", cityNames(cities))
+	}
+
+	if _, err := FindFromPlusCodeE("P27M+XF"); !errors.Is(err, ErrInvalidPlusCode) {
+		t.Errorf("Expected ErrInvalidPlusCode for a short code without locality, got %v", err)
+	}
+}
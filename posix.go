@@ -0,0 +1,190 @@
+package citytimezones
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// posixYears is how many years of future transitions must follow one rule.
+// It is long enough for the month to end on every weekday, which tells rules
+// such as "last Saturday" and "Saturday before the last Sunday" apart.
+const posixYears = 12
+
+// posixRule is a POSIX TZ transition date, Mm.w.d/time: the w-th (5 = last)
+// weekday d of month m, at a local time in seconds after midnight
+type posixRule struct {
+	month, week, weekday, seconds int
+}
+
+func (r posixRule) String() string {
+	s := fmt.Sprintf("M%d.%d.%d", r.month, r.week, r.weekday)
+	if r.seconds != 2*3600 {
+		s += "/" + posixDuration(r.seconds)
+	}
+	return s
+}
+
+// POSIXTZ returns a POSIX TZ string for the city's timezone, such as
+// "CST6CDT,M3.2.0,M11.1.0" for Chicago or "JST-9" for Tokyo, for devices
+// that have no tz database. The rule is derived from the transitions Go
+// reports for the coming years; zones whose transitions do not follow one
+// repeating rule, such as those tied to a lunar calendar, return an error.
+// FixedOffsetTZ gives an offset-only fallback for them.
+func POSIXTZ(city CityData) (string, error) {
+	loc, err := cityLocation(city)
+	if err != nil {
+		return "", err
+	}
+	return posixTZ(loc, time.Now())
+}
+
+// FixedOffsetTZ returns a POSIX TZ string with only the UTC offset in effect
+// for the city at t, such as "IST-5:30". It ignores daylight saving time.
+func FixedOffsetTZ(city CityData, t time.Time) (string, error) {
+	loc, err := cityLocation(city)
+	if err != nil {
+		return "", err
+	}
+	name, offset := t.In(loc).Zone()
+	return posixName(name) + posixDuration(-offset), nil
+}
+
+func posixTZ(loc *time.Location, from time.Time) (string, error) {
+	to := from.AddDate(posixYears, 0, 0)
+	transitions := zoneTransitions(loc, from, to)
+
+	if len(transitions) == 0 {
+		name, offset := from.In(loc).Zone()
+		return posixName(name) + posixDuration(-offset), nil
+	}
+	if len(transitions) != 2*posixYears {
+		return "", fmt.Errorf("timezone %s has %d transitions in %d years, not a repeating yearly rule", loc, len(transitions), posixYears)
+	}
+
+	var starts, ends []Transition
+	for _, t := range transitions {
+		if t.At.In(loc).IsDST() {
+			starts = append(starts, t)
+		} else {
+			ends = append(ends, t)
+		}
+	}
+	if len(starts) != posixYears || len(ends) != posixYears {
+		return "", fmt.Errorf("timezone %s does not alternate between standard and daylight time", loc)
+	}
+
+	std, dst := ends[0], starts[0]
+	for _, t := range ends {
+		if t.ToName != std.ToName || t.ToOffset != std.ToOffset {
+			return "", fmt.Errorf("timezone %s changes its standard time", loc)
+		}
+	}
+	for _, t := range starts {
+		if t.ToName != dst.ToName || t.ToOffset != dst.ToOffset {
+			return "", fmt.Errorf("timezone %s changes its daylight time", loc)
+		}
+	}
+
+	startRule, ok := commonRule(starts)
+	if !ok {
+		return "", fmt.Errorf("timezone %s starts daylight time on no repeating date", loc)
+	}
+	endRule, ok := commonRule(ends)
+	if !ok {
+		return "", fmt.Errorf("timezone %s ends daylight time on no repeating date", loc)
+	}
+
+	var b strings.Builder
+	b.WriteString(posixName(std.ToName))
+	b.WriteString(posixDuration(-std.ToOffset))
+	b.WriteString(posixName(dst.ToName))
+	if dst.ToOffset != std.ToOffset+3600 {
+		b.WriteString(posixDuration(-dst.ToOffset))
+	}
+	b.WriteString("," + startRule.String() + "," + endRule.String())
+	return b.String(), nil
+}
+
+// commonRule finds a rule that yields every one of the transitions
+func commonRule(transitions []Transition) (posixRule, bool) {
+	candidates := transitionRules(transitions[0])
+	for _, t := range transitions[1:] {
+		var kept []posixRule
+		for _, c := range candidates {
+			for _, r := range transitionRules(t) {
+				if c == r {
+					kept = append(kept, c)
+				}
+			}
+		}
+		candidates = kept
+	}
+	if len(candidates) == 0 {
+		return posixRule{}, false
+	}
+	return candidates[0], true
+}
+
+// transitionRules lists the rules that describe a transition. Besides the
+// day of the transition, the day before and after are tried with times of 24h
+// or more and negative times, an extension RFC 8536 allows, so that rules
+// like "Saturday 24:00 after the first Friday" can be written. For each day
+// the last weekday of the month comes first, which is the usual form.
+func transitionRules(t Transition) []posixRule {
+	// POSIX rule times are local wall-clock times before the transition
+	local := t.At.Add(time.Duration(t.FromOffset) * time.Second)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	seconds := int(local.Sub(midnight) / time.Second)
+
+	var rules []posixRule
+	for _, shift := range []int{0, -1, 1} {
+		day := midnight.AddDate(0, 0, shift)
+		rule := posixRule{
+			month:   int(day.Month()),
+			week:    (day.Day()-1)/7 + 1,
+			weekday: int(day.Weekday()),
+			seconds: seconds - shift*24*3600,
+		}
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if day.Day()+7 > daysInMonth && rule.week != 5 {
+			last := rule
+			last.week = 5
+			rules = append(rules, last)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// posixName quotes abbreviations that are not three or more letters, such
+// as "+03", as POSIX requires
+func posixName(name string) string {
+	if len(name) < 3 {
+		return "<" + name + ">"
+	}
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return "<" + name + ">"
+		}
+	}
+	return name
+}
+
+// posixDuration formats seconds as [-]h[:mm[:ss]]
+func posixDuration(seconds int) string {
+	sign := ""
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	h, m, s := seconds/3600, seconds/60%60, seconds%60
+	switch {
+	case s != 0:
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, h, m, s)
+	case m != 0:
+		return fmt.Sprintf("%s%d:%02d", sign, h, m)
+	default:
+		return fmt.Sprintf("%s%d", sign, h)
+	}
+}
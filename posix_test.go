@@ -0,0 +1,80 @@
+package citytimezones
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPOSIXTZ_Rules(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"America/Chicago":  "CST6CDT,M3.2.0,M11.1.0",
+		"Europe/London":    "GMT0BST,M3.5.0/1,M10.5.0",
+		"Europe/Berlin":    "CET-1CEST,M3.5.0,M10.5.0/3",
+		"Australia/Sydney": "AEST-10AEDT,M10.1.0,M4.1.0/3",
+		"Asia/Tokyo":       "JST-9",
+		"Asia/Kolkata":     "IST-5:30",
+		"Asia/Dubai":       "<+04>-4",
+		"America/Phoenix":  "MST7",
+		"Europe/Dublin":    "IST-1GMT0,M10.5.0,M3.5.0/1",
+		"America/Santiago": "<-04>4<-03>,M9.1.6/24,M4.1.6/24",
+		"America/Nuuk":     "<-02>2<-01>,M3.5.0/-1,M10.5.0/0",
+		"Asia/Jerusalem":   "IST-2IDT,M3.4.4/26,M10.5.0",
+	}
+	for tz, want := range tests {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := posixTZ(loc, from)
+		if err != nil {
+			t.Errorf("%s: expected %q, got error: %v", tz, want, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: expected %q, got %q", tz, want, got)
+		}
+	}
+}
+
+func TestPOSIXTZ_NoRepeatingRule(t *testing.T) {
+	// Morocco suspends its offset during Ramadan, which moves every year
+	loc, err := time.LoadLocation("Africa/Casablanca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := posixTZ(loc, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("Expected error for Africa/Casablanca, got %q", got)
+	}
+}
+
+func TestPOSIXTZ_City(t *testing.T) {
+	got, err := POSIXTZ(CityData{City: "Tokyo", Timezone: "Asia/Tokyo"})
+	if err != nil {
+		t.Fatalf("Expected POSIX TZ for Tokyo, got error: %v", err)
+	}
+	if got != "JST-9" {
+		t.Errorf("Expected JST-9, got %q", got)
+	}
+
+	if _, err := POSIXTZ(CityData{City: "Nowhere", Timezone: "Not/AZone"}); err == nil {
+		t.Error("Expected error for an unknown timezone")
+	}
+}
+
+func TestFixedOffsetTZ(t *testing.T) {
+	chicago := CityData{City: "Chicago", Timezone: "America/Chicago"}
+
+	winter, err := FixedOffsetTZ(chicago, time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected offset TZ, got error: %v", err)
+	}
+	if winter != "CST6" {
+		t.Errorf("Expected CST6, got %q", winter)
+	}
+
+	summer, _ := FixedOffsetTZ(chicago, time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC))
+	if summer != "CDT5" {
+		t.Errorf("Expected CDT5, got %q", summer)
+	}
+}
@@ -0,0 +1,248 @@
+package citytimezones
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// latIndex returns the positions of cities sorted by latitude, the spatial
+// index used by region queries
+func latIndex(cities []CityData) []int {
+	index := make([]int, len(cities))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool { return cities[index[i]].Lat < cities[index[j]].Lat })
+	return index
+}
+
+// latRange returns the positions of the cities with minLat <= lat <= maxLat
+func (db *Database) latRange(minLat, maxLat float64) []int {
+	lo := sort.Search(len(db.byLat), func(i int) bool { return db.cities[db.byLat[i]].Lat >= minLat })
+	hi := sort.Search(len(db.byLat), func(i int) bool { return db.cities[db.byLat[i]].Lat > maxLat })
+	if lo >= hi {
+		return nil
+	}
+	return db.byLat[lo:hi]
+}
+
+// collect returns the cities at the given positions in dataset order
+func (db *Database) collect(positions []int) []CityData {
+	sorted := append([]int(nil), positions...)
+	sort.Ints(sorted)
+	results := make([]CityData, 0, len(sorted))
+	for _, i := range sorted {
+		results = append(results, db.cities[i])
+	}
+	return results
+}
+
+// FindInBoundingBox finds all cities inside a latitude/longitude rectangle
+func FindInBoundingBox(minLat, minLng, maxLat, maxLng float64) []CityData {
+	return Default().FindInBoundingBox(minLat, minLng, maxLat, maxLng)
+}
+
+// FindInBoundingBox finds all cities in the database inside a
+// latitude/longitude rectangle, edges included. A box with minLng greater
+// than maxLng crosses the antimeridian, e.g. 170 to -170 covers 20 degrees
+// around 180. Longitudes outside [-180, 180], as map viewports produce when
+// panned, are wrapped.
+func (db *Database) FindInBoundingBox(minLat, minLng, maxLat, maxLng float64) []CityData {
+	if minLat > maxLat || anyNaN(minLat, minLng, maxLat, maxLng) {
+		return []CityData{}
+	}
+
+	allLng := maxLng-minLng >= 360
+	minLng, maxLng = wrapLng(minLng), wrapLng(maxLng)
+	inLng := func(lng float64) bool {
+		switch {
+		case allLng:
+			return true
+		case minLng <= maxLng:
+			return lng >= minLng && lng <= maxLng
+		default:
+			return lng >= minLng || lng <= maxLng
+		}
+	}
+
+	var positions []int
+	for _, i := range db.latRange(minLat, maxLat) {
+		if inLng(db.cities[i].Lng) {
+			positions = append(positions, i)
+		}
+	}
+	return db.collect(positions)
+}
+
+// FindInBoundingBoxE is like FindInBoundingBox but returns
+// ErrInvalidCoordinates for latitudes off the globe, an inverted latitude
+// range or NaN
+func FindInBoundingBoxE(minLat, minLng, maxLat, maxLng float64) ([]CityData, error) {
+	return Default().FindInBoundingBoxE(minLat, minLng, maxLat, maxLng)
+}
+
+// FindInBoundingBoxE finds all cities in the database inside a rectangle,
+// validating it first. Longitudes may still wrap and cross the antimeridian.
+func (db *Database) FindInBoundingBoxE(minLat, minLng, maxLat, maxLng float64) ([]CityData, error) {
+	if anyNaN(minLat, minLng, maxLat, maxLng) {
+		return nil, fmt.Errorf("%w: bounding box has NaN", ErrInvalidCoordinates)
+	}
+	for _, lat := range []float64{minLat, maxLat} {
+		if lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("%w: latitude %v must be between -90 and 90", ErrInvalidCoordinates, lat)
+		}
+	}
+	if minLat > maxLat {
+		return nil, fmt.Errorf("%w: minimum latitude %v is above maximum %v", ErrInvalidCoordinates, minLat, maxLat)
+	}
+	return db.FindInBoundingBox(minLat, minLng, maxLat, maxLng), nil
+}
+
+// wrapLng maps a longitude into [-180, 180], keeping 180 itself
+func wrapLng(lng float64) float64 {
+	if lng >= -180 && lng <= 180 {
+		return lng
+	}
+	return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+}
+
+func anyNaN(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindInPolygon finds all cities inside a polygon
+func FindInPolygon(ring [][2]float64, holes ...[][2]float64) []CityData {
+	return Default().FindInPolygon(ring, holes...)
+}
+
+// FindInPolygon finds all cities in the database inside a polygon given as
+// an outer ring of {lat, lng} points, with optional holes. Rings may be open
+// or closed. Edges are straight in latitude/longitude, so polygons that
+// cross the antimeridian must be split.
+func (db *Database) FindInPolygon(ring [][2]float64, holes ...[][2]float64) []CityData {
+	if len(ring) < 3 {
+		return []CityData{}
+	}
+	p := polygon{swapLatLng(ring)}
+	for _, hole := range holes {
+		p = append(p, swapLatLng(hole))
+	}
+	return db.inPolygons([]polygon{p})
+}
+
+// FindInPolygonE is like FindInPolygon but returns ErrInvalidCoordinates
+// for rings with fewer than three points or points off the globe
+func FindInPolygonE(ring [][2]float64, holes ...[][2]float64) ([]CityData, error) {
+	return Default().FindInPolygonE(ring, holes...)
+}
+
+// FindInPolygonE finds all cities in the database inside a polygon of
+// {lat, lng} points, validating its rings first
+func (db *Database) FindInPolygonE(ring [][2]float64, holes ...[][2]float64) ([]CityData, error) {
+	for i, r := range append([][][2]float64{ring}, holes...) {
+		name := "outer ring"
+		if i > 0 {
+			name = fmt.Sprintf("hole %d", i)
+		}
+		if len(r) < 3 {
+			return nil, fmt.Errorf("%w: %s has %d points, need at least 3", ErrInvalidCoordinates, name, len(r))
+		}
+		for j, p := range r {
+			if err := validateLatLng(p[0], p[1]); err != nil {
+				return nil, fmt.Errorf("%s point %d: %w", name, j, err)
+			}
+		}
+	}
+	return db.FindInPolygon(ring, holes...), nil
+}
+
+// swapLatLng turns {lat, lng} points into GeoJSON {lng, lat} order
+func swapLatLng(ring [][2]float64) [][2]float64 {
+	swapped := make([][2]float64, len(ring))
+	for i, p := range ring {
+		swapped[i] = [2]float64{p[1], p[0]}
+	}
+	return swapped
+}
+
+// FindInGeoJSON finds all cities inside the polygons of a GeoJSON Polygon
+// or MultiPolygon geometry, a Feature with one, or a FeatureCollection of
+// them. GeoJSON lists positions in longitude, latitude order.
+func FindInGeoJSON(data []byte) ([]CityData, error) {
+	return Default().FindInGeoJSON(data)
+}
+
+// FindInGeoJSON finds all cities in the database inside the polygons of a
+// GeoJSON geometry, Feature or FeatureCollection
+func (db *Database) FindInGeoJSON(data []byte) ([]CityData, error) {
+	polygons, err := parseGeoJSONPolygons(data)
+	if err != nil {
+		return nil, err
+	}
+	return db.inPolygons(polygons), nil
+}
+
+// parseGeoJSONPolygons collects the polygons of a GeoJSON object
+func parseGeoJSONPolygons(data []byte) ([]polygon, error) {
+	var object struct {
+		Type     string            `json:"type"`
+		Geometry *geoJSONGeometry  `json:"geometry"`
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse GeoJSON: %w", err)
+	}
+
+	switch object.Type {
+	case "FeatureCollection":
+		var polygons []polygon
+		for i, feature := range object.Features {
+			ps, err := parseGeoJSONPolygons(feature)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			polygons = append(polygons, ps...)
+		}
+		return polygons, nil
+	case "Feature":
+		if object.Geometry == nil {
+			return nil, errors.New("feature has no geometry")
+		}
+		return object.Geometry.polygons()
+	default:
+		var geometry geoJSONGeometry
+		if err := json.Unmarshal(data, &geometry); err != nil {
+			return nil, fmt.Errorf("failed to parse GeoJSON geometry: %w", err)
+		}
+		return geometry.polygons()
+	}
+}
+
+// inPolygons returns the cities inside any of the polygons, whose points are
+// in {lng, lat} order
+func (db *Database) inPolygons(polygons []polygon) []CityData {
+	seen := map[int]bool{}
+	var positions []int
+	for _, p := range polygons {
+		if len(p) == 0 || len(p[0]) < 3 {
+			continue
+		}
+		bounds := ringBounds(p[0])
+		for _, i := range db.latRange(bounds.minY, bounds.maxY) {
+			c := db.cities[i]
+			if !seen[i] && bounds.contains(c.Lng, c.Lat) && p.contains(c.Lng, c.Lat) {
+				seen[i] = true
+				positions = append(positions, i)
+			}
+		}
+	}
+	return db.collect(positions)
+}
@@ -0,0 +1,108 @@
+package citytimezones
+
+import "testing"
+
+func testRegionDatabase() *Database {
+	return NewDatabase([]CityData{
+		{City: "Chicago", Lat: 41.8, Lng: -87.7, Timezone: "America/Chicago"},
+		{City: "Milwaukee", Lat: 43.0, Lng: -87.9, Timezone: "America/Chicago"},
+		{City: "Detroit", Lat: 42.3, Lng: -83.0, Timezone: "America/Detroit"},
+		{City: "Suva", Lat: -18.1, Lng: 178.4, Timezone: "Pacific/Fiji"},
+		{City: "Apia", Lat: -13.8, Lng: -171.8, Timezone: "Pacific/Apia"},
+		{City: "Nuku'alofa", Lat: -21.1, Lng: -175.2, Timezone: "Pacific/Tongatapu"},
+	})
+}
+
+func cityNames(cities []CityData) []string {
+	names := make([]string, len(cities))
+	for i, c := range cities {
+		names[i] = c.City
+	}
+	return names
+}
+
+func TestFindInBoundingBox(t *testing.T) {
+	db := testRegionDatabase()
+
+	cities := db.FindInBoundingBox(41, -89, 44, -86)
+	if len(cities) != 2 || cities[0].City != "Chicago" || cities[1].City != "Milwaukee" {
+		t.Errorf("Expected Chicago and Milwaukee in dataset order, got %v", cityNames(cities))
+	}
+
+	if cities := db.FindInBoundingBox(44, -89, 41, -86); len(cities) != 0 {
+		t.Errorf("Expected no cities for inverted latitudes, got %v", cityNames(cities))
+	}
+}
+
+func TestFindInBoundingBox_Antimeridian(t *testing.T) {
+	db := testRegionDatabase()
+
+	// From 175E across 180 to 173W
+	cities := db.FindInBoundingBox(-25, 175, -10, -173)
+	if len(cities) != 2 || cities[0].City != "Suva" || cities[1].City != "Nuku'alofa" {
+		t.Errorf("Expected Suva and Nuku'alofa, got %v", cityNames(cities))
+	}
+
+	// The same box from a viewport panned past 180
+	cities = db.FindInBoundingBox(-25, 175, -10, 187)
+	if len(cities) != 2 {
+		t.Errorf("Expected 2 cities for an unwrapped viewport, got %v", cityNames(cities))
+	}
+
+	// A viewport wider than the world
+	if cities := db.FindInBoundingBox(-90, -200, 90, 200); len(cities) != 6 {
+		t.Errorf("Expected all 6 cities, got %v", cityNames(cities))
+	}
+}
+
+func TestFindInPolygon(t *testing.T) {
+	db := testRegionDatabase()
+
+	// A triangle around Lake Michigan's western shore, in {lat, lng} order
+	triangle := [][2]float64{{40, -90}, {45, -90}, {42.5, -85}}
+	cities := db.FindInPolygon(triangle)
+	if len(cities) != 2 {
+		t.Errorf("Expected Chicago and Milwaukee, got %v", cityNames(cities))
+	}
+
+	// A hole around Chicago
+	hole := [][2]float64{{41.5, -88}, {42, -88}, {42, -87.5}, {41.5, -87.5}}
+	cities = db.FindInPolygon(triangle, hole)
+	if len(cities) != 1 || cities[0].City != "Milwaukee" {
+		t.Errorf("Expected only Milwaukee, got %v", cityNames(cities))
+	}
+
+	if cities := db.FindInPolygon([][2]float64{{40, -90}, {45, -90}}); len(cities) != 0 {
+		t.Errorf("Expected no cities for a degenerate ring, got %v", cityNames(cities))
+	}
+}
+
+func TestFindInGeoJSON(t *testing.T) {
+	db := testRegionDatabase()
+
+	tests := map[string]string{
+		"geometry": `{"type": "Polygon", "coordinates": [[[-90, 40], [-80, 40], [-80, 45], [-90, 45], [-90, 40]]]}`,
+		"feature": `{"type": "Feature", "properties": {}, "geometry":
+			{"type": "Polygon", "coordinates": [[[-90, 40], [-80, 40], [-80, 45], [-90, 45], [-90, 40]]]}}`,
+		"collection": `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[-90, 40], [-85, 40], [-85, 45], [-90, 45], [-90, 40]]]}},
+			{"type": "Feature", "geometry": {"type": "MultiPolygon", "coordinates": [[[[-85, 40], [-80, 40], [-80, 45], [-85, 45], [-85, 40]]]]}}]}`,
+	}
+	for name, data := range tests {
+		cities, err := db.FindInGeoJSON([]byte(data))
+		if err != nil {
+			t.Errorf("%s: expected GeoJSON to parse, got error: %v", name, err)
+			continue
+		}
+		if len(cities) != 3 {
+			t.Errorf("%s: expected 3 cities, got %v", name, cityNames(cities))
+		}
+	}
+
+	if _, err := db.FindInGeoJSON([]byte(`{"type": "Point", "coordinates": [0, 0]}`)); err == nil {
+		t.Error("Expected error for a Point geometry")
+	}
+	if _, err := db.FindInGeoJSON([]byte(`{"type": "Feature"}`)); err == nil {
+		t.Error("Expected error for a feature without geometry")
+	}
+}
@@ -0,0 +1,135 @@
+package citytimezones
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// generation counts the datasets installed as the default database
+	generation atomic.Uint64
+
+	// reloadMu serializes reloads so that they install in call order
+	reloadMu sync.Mutex
+
+	// reloadErr holds the outcome of the most recent reload
+	reloadErrMu sync.RWMutex
+	reloadErr   error
+)
+
+// storeDefault installs db as the default database
+func storeDefault(db *Database) {
+	defaultDB.Store(db)
+	generation.Add(1)
+}
+
+// Reload replaces the default database with one loaded from the data file at
+// source, in any format LoadFile accepts. An empty source reloads the default
+// dataset: CITYTZ_DATA if set, otherwise the embedded data. The overlay named
+// by CITYTZ_OVERLAY is re-read and applied either way, and so is
+// CITYTZ_CANONICAL_ZONES.
+//
+// The new dataset, its indexes and its overlay report are built before being
+// swapped in as one Database, so
+// concurrent lookups see either the old or the new data, never a mix. If
+// loading fails, the current database stays in place and the error is
+// returned and recorded for LastReloadError.
+func Reload(source string) error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	db, err := buildDefaultDatabase(source)
+
+	reloadErrMu.Lock()
+	reloadErr = err
+	reloadErrMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	storeDefault(db)
+	return nil
+}
+
+// Generation returns the number of datasets installed as the default
+// database: 1 after initialization, incremented by each successful Reload
+func Generation() uint64 {
+	return generation.Load()
+}
+
+// LastReloadError returns the error of the most recent Reload, or nil if it
+// succeeded or no reload has happened
+func LastReloadError() error {
+	reloadErrMu.RLock()
+	defer reloadErrMu.RUnlock()
+	return reloadErr
+}
+
+// LastOverlayReport returns the report of the CITYTZ_OVERLAY overlay applied
+// to the current default database, or nil if none was applied. Its Stale
+// entries name corrections that no longer match the data. The report is
+// kept in the database, so it always describes Default(); to read both
+// together, use Default().OverlayReport().
+func LastOverlayReport() *OverlayReport {
+	return Default().OverlayReport()
+}
+
+// WatchFile polls the data file at source, and the CITYTZ_OVERLAY file if
+// set, every interval, and calls Reload(source) when either changes. An empty
+// source watches the CITYTZ_DATA file, if set. WatchFile blocks until ctx is
+// done; reload failures are available from LastReloadError.
+func WatchFile(ctx context.Context, source string, interval time.Duration) error {
+	var paths []string
+	if source != "" {
+		paths = append(paths, source)
+	} else if path := os.Getenv(dataEnvVar); path != "" {
+		paths = append(paths, path)
+	}
+	if path := os.Getenv(overlayEnvVar); path != "" {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return errors.New("nothing to watch: no source given and neither " + dataEnvVar + " nor " + overlayEnvVar + " is set")
+	}
+	if interval <= 0 {
+		return errors.New("watch interval must be positive")
+	}
+
+	last := fileStamps(paths)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			current := fileStamps(paths)
+			if current == last {
+				continue
+			}
+			last = current
+			_ = Reload(source) // recorded for LastReloadError
+		}
+	}
+}
+
+// fileStamps summarizes the modification time and size of each file, so
+// that any change to any of them yields a different value
+func fileStamps(paths []string) string {
+	stamp := ""
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			stamp += path + ":missing;"
+			continue
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp
+}
@@ -0,0 +1,186 @@
+package citytimezones
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// restoreDefault reloads the default dataset once the test finishes
+func restoreDefault(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Reload(""); err != nil {
+			t.Fatalf("Failed to restore the default dataset: %v", err)
+		}
+	})
+}
+
+func writeTestCities(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	restoreDefault(t)
+	path := filepath.Join(t.TempDir(), "cities.json")
+	writeTestCities(t, path, testCitiesJSON)
+
+	before := Generation()
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	if Generation() != before+1 {
+		t.Errorf("Expected generation %d, got %d", before+1, Generation())
+	}
+	if got := len(GetCityMapping()); got != 2 {
+		t.Errorf("Expected 2 cities after reload, got %d", got)
+	}
+	if LastReloadError() != nil {
+		t.Errorf("Expected no reload error, got %v", LastReloadError())
+	}
+
+	// A failed reload keeps the current data and records the error
+	if err := Reload(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("Expected reload of a missing file to fail")
+	}
+	if Generation() != before+1 {
+		t.Errorf("Expected generation to stay %d after a failed reload, got %d", before+1, Generation())
+	}
+	if got := len(GetCityMapping()); got != 2 {
+		t.Errorf("Expected the previous 2 cities to stay loaded, got %d", got)
+	}
+	if LastReloadError() == nil {
+		t.Error("Expected LastReloadError to report the failed reload")
+	}
+}
+
+func TestReload_ConcurrentReaders(t *testing.T) {
+	restoreDefault(t)
+	path := filepath.Join(t.TempDir(), "cities.json")
+	writeTestCities(t, path, testCitiesJSON)
+	full := len(GetCityMapping())
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Each snapshot is one complete dataset and its ID index
+				db := Default()
+				cities := db.Cities()
+				if len(cities) != 2 && len(cities) != full {
+					t.Errorf("Saw a partial dataset of %d cities", len(cities))
+					return
+				}
+				if _, ok := db.GetByID(cities[0].ID); !ok {
+					t.Errorf("ID index does not match dataset")
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		source := path
+		if i%2 == 1 {
+			source = ""
+		}
+		if err := Reload(source); err != nil {
+			t.Fatalf("Reload %d failed: %v", i, err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestWatchFile(t *testing.T) {
+	restoreDefault(t)
+	path := filepath.Join(t.TempDir(), "cities.json")
+	writeTestCities(t, path, testCitiesJSON)
+	if err := Reload(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- WatchFile(ctx, path, 10*time.Millisecond) }()
+
+	// Give the watcher time to record the initial state, then edit the file
+	time.Sleep(50 * time.Millisecond)
+	writeTestCities(t, path, `[{"city": "Omega", "lat": 0, "lng": 0, "country": "Testland", "timezone": "UTC"}]`)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(LookupViaCity("Omega")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the watcher to reload the edited file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled from WatchFile, got %v", err)
+	}
+}
+
+func TestWatchFile_NothingToWatch(t *testing.T) {
+	t.Setenv(dataEnvVar, "")
+	t.Setenv(overlayEnvVar, "")
+	if err := WatchFile(context.Background(), "", time.Second); err == nil {
+		t.Error("Expected an error when there is nothing to watch")
+	}
+}
+
+func TestReload_OverlayReport(t *testing.T) {
+	restoreDefault(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cities.json")
+	writeTestCities(t, path, testCitiesJSON)
+	overlayPath := filepath.Join(dir, "overlay.json")
+	writeTestCities(t, overlayPath, `{"remove": [{"city": "Alpha", "province": "North", "country": "Testland"}, {"city": "Atlantis", "country": "Nowhere"}]}`)
+
+	t.Setenv(overlayEnvVar, overlayPath)
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	report := LastOverlayReport()
+	if report == nil {
+		t.Fatal("Expected the overlay report to be kept")
+	}
+	if report.Removed != 1 || len(report.Stale) != 1 || report.Stale[0].Key.City != "Atlantis" {
+		t.Errorf("Expected Alpha removed and Atlantis stale, got %+v", report)
+	}
+	if db := Default(); db.OverlayReport() != report || len(db.Cities()) != 1 {
+		t.Errorf("Expected the report to belong to the installed database, got %+v", db.OverlayReport())
+	}
+
+	// Canonicalizing the zones keeps the report with the data
+	t.Setenv(canonicalZonesEnvVar, "true")
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	if report := LastOverlayReport(); report == nil || report.Removed != 1 {
+		t.Errorf("Expected the overlay report to survive canonicalization, got %+v", report)
+	}
+	t.Setenv(canonicalZonesEnvVar, "")
+
+	t.Setenv(overlayEnvVar, "")
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	if report := LastOverlayReport(); report != nil {
+		t.Errorf("Expected no overlay report without an overlay, got %+v", report)
+	}
+}
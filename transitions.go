@@ -0,0 +1,107 @@
+package citytimezones
+
+import (
+	"sort"
+	"time"
+)
+
+// Transition is an instant at which a timezone changes its UTC offset or
+// abbreviation, such as the start or end of daylight saving time
+type Transition struct {
+	At         time.Time // UTC
+	FromName   string    // abbreviation before, e.g. "CST"
+	FromOffset int       // seconds east of UTC before
+	ToName     string    // abbreviation after, e.g. "CDT"
+	ToOffset   int       // seconds east of UTC after
+}
+
+// ZoneTransitions lists the transitions of one timezone together with the
+// cities that use it
+type ZoneTransitions struct {
+	Timezone    string
+	Transitions []Transition
+	Cities      []CityData
+}
+
+// Transitions returns the transitions of the timezone tz in [from, to), from
+// Go's time zone database. It returns nil if tz cannot be loaded.
+func Transitions(tz string, from, to time.Time) []Transition {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil
+	}
+	return zoneTransitions(loc, from, to)
+}
+
+func zoneTransitions(loc *time.Location, from, to time.Time) []Transition {
+	var transitions []Transition
+	add := func(before, after time.Time) {
+		fromName, fromOffset := before.Zone()
+		toName, toOffset := after.Zone()
+		if fromName != toName || fromOffset != toOffset {
+			transitions = append(transitions, Transition{
+				At:         after.UTC(),
+				FromName:   fromName,
+				FromOffset: fromOffset,
+				ToName:     toName,
+				ToOffset:   toOffset,
+			})
+		}
+	}
+
+	t := from.In(loc)
+	// A transition exactly at from belongs to the range
+	if start, _ := t.ZoneBounds(); start.Equal(t) && t.Before(to) {
+		add(t.Add(-time.Nanosecond), t)
+	}
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.After(t) || !end.Before(to) {
+			break
+		}
+		add(t, end)
+		t = end
+	}
+	return transitions
+}
+
+// UpcomingTransitions returns every timezone in the dataset that changes
+// offset or abbreviation in the next days days
+func UpcomingTransitions(days int) []ZoneTransitions {
+	return Default().UpcomingTransitions(days)
+}
+
+// UpcomingTransitions returns every timezone in the database that changes
+// offset or abbreviation in the next days days, each with the cities it
+// affects, ordered by the first transition
+func (db *Database) UpcomingTransitions(days int) []ZoneTransitions {
+	now := time.Now()
+	return db.transitionsBetween(now, now.AddDate(0, 0, days))
+}
+
+func (db *Database) transitionsBetween(from, to time.Time) []ZoneTransitions {
+	cities := map[string][]CityData{}
+	for _, c := range db.cities {
+		if c.Timezone != "" {
+			cities[c.Timezone] = append(cities[c.Timezone], c)
+		}
+	}
+
+	var zones []ZoneTransitions
+	for tz, zoneCities := range cities {
+		transitions := Transitions(tz, from, to)
+		if len(transitions) == 0 {
+			continue
+		}
+		zones = append(zones, ZoneTransitions{Timezone: tz, Transitions: transitions, Cities: zoneCities})
+	}
+
+	sort.Slice(zones, func(i, j int) bool {
+		a, b := zones[i].Transitions[0].At, zones[j].Transitions[0].At
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return zones[i].Timezone < zones[j].Timezone
+	})
+	return zones
+}
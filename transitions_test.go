@@ -0,0 +1,108 @@
+package citytimezones
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransitions_Chicago(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transitions := Transitions("America/Chicago", from, to)
+	if len(transitions) != 2 {
+		t.Fatalf("Expected 2 transitions in 2024, got %d", len(transitions))
+	}
+
+	spring := transitions[0]
+	if !spring.At.Equal(time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected spring forward at 2024-03-10 08:00 UTC, got %s", spring.At)
+	}
+	if spring.FromName != "CST" || spring.FromOffset != -6*3600 || spring.ToName != "CDT" || spring.ToOffset != -5*3600 {
+		t.Errorf("Expected CST-6 to CDT-5, got %+v", spring)
+	}
+
+	fall := transitions[1]
+	if !fall.At.Equal(time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected fall back at 2024-11-03 07:00 UTC, got %s", fall.At)
+	}
+	if fall.ToName != "CST" {
+		t.Errorf("Expected CST after fall back, got %s", fall.ToName)
+	}
+}
+
+func TestTransitions_Boundaries(t *testing.T) {
+	spring := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	fall := time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC)
+
+	// from is inclusive
+	transitions := Transitions("America/Chicago", spring, fall.Add(time.Hour))
+	if len(transitions) != 2 || !transitions[0].At.Equal(spring) {
+		t.Fatalf("Expected the transition at from to be included, got %+v", transitions)
+	}
+	if transitions[0].FromName != "CST" || transitions[0].ToName != "CDT" {
+		t.Errorf("Expected CST to CDT at from, got %+v", transitions[0])
+	}
+
+	// to is exclusive
+	transitions = Transitions("America/Chicago", spring.Add(time.Second), fall)
+	if len(transitions) != 0 {
+		t.Errorf("Expected no transitions in (spring, fall), got %+v", transitions)
+	}
+
+	// An empty range has no transitions, even at a transition
+	if transitions := Transitions("America/Chicago", spring, spring); len(transitions) != 0 {
+		t.Errorf("Expected no transitions in an empty range, got %+v", transitions)
+	}
+}
+
+func TestTransitions_NoDST(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if transitions := Transitions("Asia/Tokyo", from, to); len(transitions) != 0 {
+		t.Errorf("Expected no transitions for Asia/Tokyo, got %v", transitions)
+	}
+	if transitions := Transitions("Not/AZone", from, to); transitions != nil {
+		t.Errorf("Expected nil for unknown zone, got %v", transitions)
+	}
+}
+
+func TestTransitions_SouthernHemisphere(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transitions := Transitions("Australia/Sydney", from, to)
+	if len(transitions) != 2 {
+		t.Fatalf("Expected 2 transitions in 2024, got %d", len(transitions))
+	}
+	if transitions[0].ToOffset >= transitions[0].FromOffset {
+		t.Errorf("Expected Sydney to leave DST first, got %+v", transitions[0])
+	}
+}
+
+func TestUpcomingTransitions(t *testing.T) {
+	db := NewDatabase([]CityData{
+		{City: "Chicago", Timezone: "America/Chicago"},
+		{City: "Milwaukee", Timezone: "America/Chicago"},
+		{City: "London", Timezone: "Europe/London"},
+		{City: "Tokyo", Timezone: "Asia/Tokyo"},
+	})
+
+	// The US changes clocks two weeks before Europe
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	zones := db.transitionsBetween(from, from.AddDate(0, 0, 31))
+	if len(zones) != 2 {
+		t.Fatalf("Expected 2 zones, got %d", len(zones))
+	}
+	if zones[0].Timezone != "America/Chicago" || zones[1].Timezone != "Europe/London" {
+		t.Errorf("Expected Chicago before London, got %s, %s", zones[0].Timezone, zones[1].Timezone)
+	}
+	if len(zones[0].Cities) != 2 {
+		t.Errorf("Expected 2 cities in America/Chicago, got %d", len(zones[0].Cities))
+	}
+
+	if upcoming := db.UpcomingTransitions(400); len(upcoming) != 2 {
+		t.Errorf("Expected 2 zones with transitions within 400 days, got %d", len(upcoming))
+	}
+}
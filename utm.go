@@ -0,0 +1,261 @@
+package citytimezones
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WGS84 ellipsoid and UTM projection constants
+const (
+	wgs84A      = 6378137.0
+	wgs84E2     = 0.00669438 // eccentricity squared
+	utmK0       = 0.9996
+	utmFalseE   = 500000.0
+	utmFalseN   = 10000000.0 // added to northings in the southern hemisphere
+	mgrsLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	mgrsRows    = "ABCDEFGHJKLMNPQRSTUV"
+	mgrsBands   = "CDEFGHJKLMNPQRSTUVWX"
+
+	// utmTolerance is how far, in degrees, a UTM coordinate may stray past
+	// its band and zone
+	utmTolerance = 0.5
+)
+
+// mgrsMinNorthing is the smallest northing of each latitude band, used to
+// pick the 2000km cycle of an MGRS row letter
+var mgrsMinNorthing = map[byte]float64{
+	'C': 1100000, 'D': 2000000, 'E': 2800000, 'F': 3700000, 'G': 4600000,
+	'H': 5500000, 'J': 6400000, 'K': 7300000, 'L': 8200000, 'M': 9100000,
+	'N': 0, 'P': 800000, 'Q': 1700000, 'R': 2600000, 'S': 3500000,
+	'T': 4400000, 'U': 5300000, 'V': 6200000, 'W': 7000000, 'X': 7900000,
+}
+
+// ParseUTM parses a UTM coordinate of zone, latitude band, easting and
+// northing in metres, such as "16T 448251 4636135" or "16T 448251E
+// 4636135N". The band letter (C-X) gives the hemisphere: N and above are
+// north of the equator.
+func ParseUTM(s string) (lat, lng float64, err error) {
+	fields := strings.Fields(strings.TrimSpace(s))
+	// Allow a space between the zone number and the band
+	if len(fields) == 4 {
+		fields = append([]string{fields[0] + fields[1]}, fields[2:]...)
+	}
+	if len(fields) != 3 {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: expected zone and band, easting and northing, e.g. \"16T 448251 4636135\"", s)
+	}
+
+	zone, band, err := parseUTMZone(fields[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: %v", s, err)
+	}
+	easting, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(fields[1]), "E"), 64)
+	if err != nil || easting < 100000 || easting > 900000 {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: easting %q must be 100000-900000 metres", s, fields[1])
+	}
+	northing, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(fields[2]), "N"), 64)
+	if err != nil || northing < 0 || northing > utmFalseN {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: northing %q must be 0-10000000 metres", s, fields[2])
+	}
+
+	lat, lng = utmToLatLng(zone, band >= 'N', easting, northing)
+
+	// The point must lie in its band and zone, give or take a little for
+	// coordinates carried just past a boundary
+	west, east, ok := utmZoneBounds(zone, band)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: zone %d%c is not used", s, zone, band)
+	}
+	if south, north := mgrsBandBounds(band); lat < south-utmTolerance || lat > north+utmTolerance {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: latitude %.4f is outside band %c (%v to %v)", s, lat, band, south, north)
+	}
+	if lng < west-utmTolerance || lng > east+utmTolerance {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: longitude %.4f is outside zone %d (%v to %v)", s, lng, zone, west, east)
+	}
+	if err := validateLatLng(lat, lng); err != nil {
+		return 0, 0, fmt.Errorf("invalid UTM coordinate %q: %w", s, err)
+	}
+	return lat, lng, nil
+}
+
+// ParseMGRS parses a Military Grid Reference System coordinate, such as
+// "18TWL8073504695" or "18T WL 80735 04695", at any precision from 100km
+// (no digits) to 1m (5+5 digits), and returns the center of the square
+func ParseMGRS(s string) (lat, lng float64, err error) {
+	compact := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+
+	i := 0
+	for i < len(compact) && i < 2 && compact[i] >= '0' && compact[i] <= '9' {
+		i++
+	}
+	if i == 0 || len(compact) < i+3 {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: expected zone, band and 100km square, e.g. \"18TWL8073504695\"", s)
+	}
+	zone, band, err := parseUTMZone(compact[:i+1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: %v", s, err)
+	}
+
+	column, row := compact[i+1], compact[i+2]
+	digits := compact[i+3:]
+	if len(digits)%2 != 0 || len(digits) > 10 {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: easting and northing need the same number of digits, at most 5 each", s)
+	}
+	for _, d := range digits {
+		if d < '0' || d > '9' {
+			return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: %q is not a digit", s, d)
+		}
+	}
+
+	// The 100km square letters repeat in sets of six zones: columns run
+	// A-H, J-R, S-Z, and rows start at A in odd sets and at F in even ones
+	set := (zone-1)%6 + 1
+	colIndex := strings.IndexByte(mgrsLetters, column) - ((set-1)%3)*8
+	if colIndex < 0 || colIndex >= 8 {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: column letter %c is not used in zone %d", s, column, zone)
+	}
+	rowIndex := strings.IndexByte(mgrsRows, row)
+	if rowIndex < 0 {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: invalid row letter %c", s, row)
+	}
+	if set%2 == 0 {
+		rowIndex = (rowIndex - 5 + 20) % 20
+	}
+
+	easting := float64(colIndex+1) * 100000
+	northing := float64(rowIndex) * 100000
+	for northing < mgrsMinNorthing[band] {
+		northing += 2000000
+	}
+
+	// Digits give the offset within the square; use the center of the
+	// remaining precision
+	precision := len(digits) / 2
+	scale := math.Pow(10, float64(5-precision))
+	if precision > 0 {
+		e, _ := strconv.Atoi(digits[:precision])
+		n, _ := strconv.Atoi(digits[precision:])
+		easting += float64(e) * scale
+		northing += float64(n) * scale
+	}
+	easting += scale / 2
+	northing += scale / 2
+
+	// The square must overlap the zone and band it is given in, which rules
+	// out columns past the edge of a narrow zone and rows of another band
+	west, east, ok := utmZoneBounds(zone, band)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: zone %d%c is not used", s, zone, band)
+	}
+	south, north := mgrsBandBounds(band)
+	minLat, minLng, maxLat, maxLng := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, de := range []float64{-scale / 2, scale / 2} {
+		for _, dn := range []float64{-scale / 2, scale / 2} {
+			cornerLat, cornerLng := utmToLatLng(zone, band >= 'N', easting+de, northing+dn)
+			minLat, maxLat = math.Min(minLat, cornerLat), math.Max(maxLat, cornerLat)
+			minLng, maxLng = math.Min(minLng, cornerLng), math.Max(maxLng, cornerLng)
+		}
+	}
+	if maxLng <= west || minLng >= east {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: square %c%c lies outside zone %d", s, column, row, zone)
+	}
+	if maxLat <= south || minLat >= north {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: row letter %c is not used in band %c", s, row, band)
+	}
+
+	lat, lng = utmToLatLng(zone, band >= 'N', easting, northing)
+	if err := validateLatLng(lat, lng); err != nil {
+		return 0, 0, fmt.Errorf("invalid MGRS coordinate %q: %w", s, err)
+	}
+	return lat, lng, nil
+}
+
+// utmZoneBounds returns the longitudes a UTM zone covers in a latitude band,
+// with the Norway (31V, 32V) and Svalbard (31X-37X) exceptions. Zones 32X,
+// 34X and 36X do not exist.
+func utmZoneBounds(zone int, band byte) (west, east float64, ok bool) {
+	west = float64(zone-1)*6 - 180
+	east = west + 6
+	switch {
+	case band == 'V' && zone == 31:
+		east = 3
+	case band == 'V' && zone == 32:
+		west = 3
+	case band == 'X' && (zone == 32 || zone == 34 || zone == 36):
+		return 0, 0, false
+	case band == 'X' && zone == 31:
+		east = 9
+	case band == 'X' && zone == 33:
+		west, east = 9, 21
+	case band == 'X' && zone == 35:
+		west, east = 21, 33
+	case band == 'X' && zone == 37:
+		west = 33
+	}
+	return west, east, true
+}
+
+// mgrsBandBounds returns the latitudes of a band: 8 degrees each from -80,
+// except X, which runs from 72 to 84
+func mgrsBandBounds(band byte) (south, north float64) {
+	south = -80 + 8*float64(strings.IndexByte(mgrsBands, band))
+	if band == 'X' {
+		return south, 84
+	}
+	return south, south + 8
+}
+
+// parseUTMZone parses a zone number (1-60) followed by a latitude band
+// letter (C-X, without I and O)
+func parseUTMZone(s string) (zone int, band byte, err error) {
+	s = strings.ToUpper(s)
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("zone %q needs a number and a latitude band", s)
+	}
+	zone, err = strconv.Atoi(s[:len(s)-1])
+	if err != nil || zone < 1 || zone > 60 {
+		return 0, 0, fmt.Errorf("zone %q must be 1-60", s[:len(s)-1])
+	}
+	band = s[len(s)-1]
+	if _, ok := mgrsMinNorthing[band]; !ok {
+		return 0, 0, fmt.Errorf("latitude band %c must be C-X (polar UPS bands are not supported)", band)
+	}
+	return zone, band, nil
+}
+
+// utmToLatLng converts UTM to WGS84 latitude and longitude with the series
+// expansion in Snyder, "Map Projections: A Working Manual" (1987), accurate
+// to well under a metre within a zone
+func utmToLatLng(zone int, north bool, easting, northing float64) (lat, lng float64) {
+	ep2 := wgs84E2 / (1 - wgs84E2)
+	x := easting - utmFalseE
+	y := northing
+	if !north {
+		y -= utmFalseN
+	}
+
+	m := y / utmK0
+	mu := m / (wgs84A * (1 - wgs84E2/4 - 3*wgs84E2*wgs84E2/64 - 5*wgs84E2*wgs84E2*wgs84E2/256))
+	e1 := (1 - math.Sqrt(1-wgs84E2)) / (1 + math.Sqrt(1-wgs84E2))
+	phi1 := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sin, cos, tan := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	n1 := wgs84A / math.Sqrt(1-wgs84E2*sin*sin)
+	t1 := tan * tan
+	c1 := ep2 * cos * cos
+	r1 := wgs84A * (1 - wgs84E2) / math.Pow(1-wgs84E2*sin*sin, 1.5)
+	d := x / (n1 * utmK0)
+
+	lat = phi1 - (n1*tan/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lng = (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120) / cos
+
+	centralMeridian := float64(zone-1)*6 - 180 + 3
+	return lat * 180 / math.Pi, centralMeridian + lng*180/math.Pi
+}
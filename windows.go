@@ -0,0 +1,134 @@
+package citytimezones
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// Windows zone table from CLDR, regenerated by
+// "go run ./cmd/sync-data windows-zones"
+//
+//go:embed data/windowsZones.txt
+var embeddedWindowsZones string
+
+// windowsMapping is one CLDR mapZone entry for an IANA zone
+type windowsMapping struct {
+	id        string // Windows zone ID, e.g. "Central Standard Time"
+	territory string // ISO 3166 alpha-2 code, or "001" for the default
+	zone      string // IANA zone as CLDR spells it
+}
+
+// windowsTable indexes the Windows zone table by IANA zone, under both the
+// name CLDR uses and its canonical name
+type windowsTable struct {
+	byZone map[string][]windowsMapping
+}
+
+var (
+	windowsOnce  sync.Once
+	windowsZones *windowsTable
+
+	// windowsCache remembers resolved zones, keyed by zone and territory
+	windowsCacheMu sync.Mutex
+	windowsCache   = map[string]string{}
+)
+
+// parseWindowsZones reads the tab-separated table written by
+// "sync-data windows-zones": Windows ID, territory, IANA zones
+func parseWindowsZones(text string) *windowsTable {
+	table := &windowsTable{byZone: make(map[string][]windowsMapping)}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		for _, zone := range strings.Fields(fields[2]) {
+			m := windowsMapping{id: fields[0], territory: fields[1], zone: zone}
+			table.byZone[zone] = append(table.byZone[zone], m)
+			if canonical := CanonicalZone(zone); canonical != zone {
+				table.byZone[canonical] = append(table.byZone[canonical], m)
+			}
+		}
+	}
+	return table
+}
+
+// WindowsZoneFor returns the Windows timezone ID of a city, such as "Central
+// Standard Time" for Chicago, using the CLDR mapping for the city's timezone
+// and ISO2 territory. The zone is looked up as spelled and by its canonical
+// name. It returns "" for zones that CLDR does not map.
+func WindowsZoneFor(city CityData) string {
+	tz := strings.TrimSpace(city.Timezone)
+	if tz == "" {
+		return ""
+	}
+	territory, _ := city.ISO2.(string)
+	territory = strings.ToUpper(territory)
+
+	key := tz + "|" + territory
+	windowsCacheMu.Lock()
+	id, ok := windowsCache[key]
+	windowsCacheMu.Unlock()
+	if ok {
+		return id
+	}
+
+	windowsOnce.Do(func() {
+		windowsZones = parseWindowsZones(embeddedWindowsZones)
+	})
+	id = windowsZones.lookup(tz, territory)
+	windowsCacheMu.Lock()
+	windowsCache[key] = id
+	windowsCacheMu.Unlock()
+	return id
+}
+
+// lookup prefers the mapping for the territory, then the default mapping,
+// then any mapping of the zone, trying the zone's own name before its
+// canonical name
+func (t *windowsTable) lookup(tz, territory string) string {
+	mappings := t.byZone[tz]
+	if canonical := CanonicalZone(tz); canonical != tz {
+		mappings = append(mappings[:len(mappings):len(mappings)], t.byZone[canonical]...)
+	}
+	for _, m := range mappings {
+		if m.territory == territory {
+			return m.id
+		}
+	}
+	for _, m := range mappings {
+		if m.territory == "001" {
+			return m.id
+		}
+	}
+	if len(mappings) > 0 {
+		return mappings[0].id
+	}
+	return ""
+}
+
+// CitiesForWindowsZone returns the cities whose Windows timezone ID is id
+func CitiesForWindowsZone(id string) []CityData {
+	return Default().CitiesForWindowsZone(id)
+}
+
+// CitiesForWindowsZone returns the cities in the database whose Windows
+// timezone ID, as given by WindowsZoneFor, is id (case-insensitive)
+func (db *Database) CitiesForWindowsZone(id string) []CityData {
+	var results []CityData
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return results
+	}
+
+	for _, c := range db.cities {
+		if strings.EqualFold(WindowsZoneFor(c), id) {
+			results = append(results, c)
+		}
+	}
+	return results
+}
@@ -0,0 +1,124 @@
+package citytimezones
+
+import "testing"
+
+func TestWindowsZoneFor(t *testing.T) {
+	tests := []struct {
+		city CityData
+		want string
+	}{
+		{CityData{City: "Chicago", ISO2: "US", Timezone: "America/Chicago"}, "Central Standard Time"},
+		{CityData{City: "Detroit", ISO2: "US", Timezone: "America/Detroit"}, "Eastern Standard Time"},
+		{CityData{City: "Kolkata", ISO2: "IN", Timezone: "Asia/Calcutta"}, "India Standard Time"},
+		{CityData{City: "Honolulu", ISO2: "US", Timezone: "Pacific/Honolulu"}, "Hawaiian Standard Time"},
+		{CityData{City: "Oslo", ISO2: "NO", Timezone: "Europe/Oslo"}, "W. Europe Standard Time"},
+		{CityData{City: "Stockholm", ISO2: "SE", Timezone: "Europe/Stockholm"}, "W. Europe Standard Time"},
+		{CityData{City: "Rome", ISO2: "IT", Timezone: "Europe/Rome"}, "W. Europe Standard Time"},
+		{CityData{City: "Amsterdam", ISO2: "NL", Timezone: "Europe/Amsterdam"}, "W. Europe Standard Time"},
+		{CityData{City: "Vienna", ISO2: "AT", Timezone: "Europe/Vienna"}, "W. Europe Standard Time"},
+		{CityData{City: "Madrid", ISO2: "ES", Timezone: "Europe/Madrid"}, "Romance Standard Time"},
+		{CityData{City: "Paris", ISO2: "FR", Timezone: "Europe/Paris"}, "Romance Standard Time"},
+		{CityData{City: "Prague", ISO2: "CZ", Timezone: "Europe/Prague"}, "Central Europe Standard Time"},
+		{CityData{City: "Warsaw", ISO2: "PL", Timezone: "Europe/Warsaw"}, "Central European Standard Time"},
+		{CityData{City: "Kyiv", ISO2: "UA", Timezone: "Europe/Kyiv"}, "FLE Standard Time"},
+		{CityData{City: "Lima", ISO2: "PE", Timezone: "America/Lima"}, "SA Pacific Standard Time"},
+		{CityData{City: "Bogota", ISO2: "CO", Timezone: "America/Bogota"}, "SA Pacific Standard Time"},
+		{CityData{City: "Buenos Aires", ISO2: "AR", Timezone: "America/Argentina/Buenos_Aires"}, "Argentina Standard Time"},
+		{CityData{City: "Santiago", ISO2: "CL", Timezone: "America/Santiago"}, "Pacific SA Standard Time"},
+		{CityData{City: "Sao Paulo", ISO2: "BR", Timezone: "America/Sao_Paulo"}, "E. South America Standard Time"},
+		{CityData{City: "Manaus", ISO2: "BR", Timezone: "America/Manaus"}, "SA Western Standard Time"},
+		{CityData{City: "Manila", ISO2: "PH", Timezone: "Asia/Manila"}, "Singapore Standard Time"},
+		{CityData{City: "Kuala Lumpur", ISO2: "MY", Timezone: "Asia/Kuala_Lumpur"}, "Singapore Standard Time"},
+		{CityData{City: "Jakarta", ISO2: "ID", Timezone: "Asia/Jakarta"}, "SE Asia Standard Time"},
+		{CityData{City: "Ho Chi Minh City", ISO2: "VN", Timezone: "Asia/Ho_Chi_Minh"}, "SE Asia Standard Time"},
+		{CityData{City: "Hong Kong", ISO2: "HK", Timezone: "Asia/Hong_Kong"}, "China Standard Time"},
+		{CityData{City: "Seoul", ISO2: "KR", Timezone: "Asia/Seoul"}, "Korea Standard Time"},
+		{CityData{City: "Nowhere", Timezone: "Not/AZone"}, ""},
+		{CityData{City: "Nowhere"}, ""},
+	}
+	for _, tt := range tests {
+		if got := WindowsZoneFor(tt.city); got != tt.want {
+			t.Errorf("WindowsZoneFor(%s): expected %q, got %q", tt.city.City, tt.want, got)
+		}
+	}
+}
+
+func TestWindowsTable_Unmapped(t *testing.T) {
+	// Zones missing from the table have no mapping, even when a listed zone
+	// keeps the same time
+	table := parseWindowsZones("Central Europe Standard Time\t001\tEurope/Budapest\n")
+	if got := table.lookup("Europe/Oslo", "NO"); got != "" {
+		t.Errorf("Expected no mapping for an unlisted zone, got %q", got)
+	}
+}
+
+func TestWindowsTable_Territory(t *testing.T) {
+	table := parseWindowsZones("# sample\n" +
+		"Central Standard Time\t001\tAmerica/Chicago\n" +
+		"Central Standard Time\tUS\tAmerica/Chicago America/Indiana/Knox\n" +
+		"Central Standard Time (Mexico)\tMX\tAmerica/Mexico_City\n" +
+		"Eastern Standard Time\t001\tAmerica/New_York\n" +
+		"US Eastern Standard Time\tUS\tAmerica/Indianapolis\n")
+
+	if got := table.lookup("America/Indiana/Knox", "US"); got != "Central Standard Time" {
+		t.Errorf("Expected Central Standard Time, got %q", got)
+	}
+	if got := table.lookup("America/Mexico_City", "MX"); got != "Central Standard Time (Mexico)" {
+		t.Errorf("Expected Central Standard Time (Mexico), got %q", got)
+	}
+	// Legacy names in the table are also indexed under their canonical zone
+	if got := table.lookup("America/Indiana/Indianapolis", "US"); got != "US Eastern Standard Time" {
+		t.Errorf("Expected US Eastern Standard Time, got %q", got)
+	}
+}
+
+func TestCitiesForWindowsZone(t *testing.T) {
+	db := NewDatabase([]CityData{
+		{City: "Chicago", ISO2: "US", Timezone: "America/Chicago"},
+		{City: "Milwaukee", ISO2: "US", Timezone: "America/Chicago"},
+		{City: "London", ISO2: "GB", Timezone: "Europe/London"},
+	})
+
+	cities := db.CitiesForWindowsZone("central standard time")
+	if len(cities) != 2 {
+		t.Errorf("Expected 2 cities, got %d", len(cities))
+	}
+	if cities := db.CitiesForWindowsZone(""); len(cities) != 0 {
+		t.Errorf("Expected no cities for an empty ID, got %d", len(cities))
+	}
+}
+
+func TestWindowsZoneFor_EveryMappedDatasetZone(t *testing.T) {
+	table := parseWindowsZones(embeddedWindowsZones)
+	for _, city := range Default().Cities() {
+		tz := city.Timezone
+		if len(table.byZone[tz]) == 0 && len(table.byZone[CanonicalZone(tz)]) == 0 {
+			continue
+		}
+		if WindowsZoneFor(city) == "" {
+			t.Errorf("Expected a Windows zone for %s (%s), which CLDR maps", KeyOf(city), tz)
+		}
+	}
+}
+
+func TestWindowsTable_LegacyRows(t *testing.T) {
+	// CLDR spells these zones the legacy way, and tzdata links the legacy
+	// spelling to a different zone than the current name
+	table := parseWindowsZones("E. Africa Standard Time\t001\tAfrica/Nairobi\n" +
+		"E. Africa Standard Time\tER\tAfrica/Asmera\n" +
+		"SA Pacific Standard Time\tCA\tAmerica/Coral_Harbour\n" +
+		"Central Pacific Standard Time\tFM\tPacific/Ponape Pacific/Kosrae\n")
+
+	tests := []struct{ tz, territory, want string }{
+		{"Africa/Asmara", "ER", "E. Africa Standard Time"},
+		{"Africa/Asmera", "ER", "E. Africa Standard Time"},
+		{"America/Atikokan", "CA", "SA Pacific Standard Time"},
+		{"Pacific/Pohnpei", "FM", "Central Pacific Standard Time"},
+		{"Pacific/Ponape", "FM", "Central Pacific Standard Time"},
+	}
+	for _, tt := range tests {
+		if got := table.lookup(tt.tz, tt.territory); got != tt.want {
+			t.Errorf("%s in %s: expected %q, got %q", tt.tz, tt.territory, tt.want, got)
+		}
+	}
+}
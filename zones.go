@@ -0,0 +1,139 @@
+package citytimezones
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Zone aliases from tzdata, regenerated by "go run ./cmd/sync-data links".
+// Each line is "alias canonical kind"; see the file header for the kinds.
+//
+//go:embed data/tzlinks.txt
+var embeddedZoneLinks string
+
+var (
+	zoneAliasesOnce sync.Once
+	zoneAliases     map[string]string
+)
+
+// loadZoneAliases parses the embedded alias table on first use
+func loadZoneAliases() map[string]string {
+	zoneAliasesOnce.Do(func() {
+		zoneAliases = make(map[string]string)
+		for _, line := range strings.Split(embeddedZoneLinks, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+				zoneAliases[fields[0]] = fields[1]
+			}
+		}
+	})
+	return zoneAliases
+}
+
+// CanonicalZone returns the canonical IANA name of a timezone, resolving
+// legacy and deprecated links such as "Asia/Calcutta" (Asia/Kolkata) and
+// "US/Central" (America/Chicago). Current names that tzdata links to another
+// zone with the same clocks since 1970, such as "Europe/Bratislava"
+// (Europe/Prague), are resolved too. Canonical and unknown names are returned
+// unchanged.
+func CanonicalZone(tz string) string {
+	tz = strings.TrimSpace(tz)
+	if canonical, ok := loadZoneAliases()[tz]; ok {
+		return canonical
+	}
+	return tz
+}
+
+// ZonesEquivalent reports whether two timezones keep the same time in
+// [from, to): the same canonical zone, or zones with the same UTC offset at
+// from and the same offset changes within the range. Zones that tzdata keeps
+// apart only for their history before from, such as Europe/Amsterdam and
+// Europe/Brussels, are equivalent for recent ranges.
+func ZonesEquivalent(a, b string, from, to time.Time) bool {
+	a, b = CanonicalZone(a), CanonicalZone(b)
+	if a == b {
+		return true
+	}
+
+	locA, err := time.LoadLocation(a)
+	if err != nil {
+		return false
+	}
+	locB, err := time.LoadLocation(b)
+	if err != nil {
+		return false
+	}
+	return sameRules(locA, locB, from, to)
+}
+
+// sameRules reports whether two locations have the same UTC offset at from
+// and the same offset changes within [from, to)
+func sameRules(locA, locB *time.Location, from, to time.Time) bool {
+	_, offsetA := from.In(locA).Zone()
+	_, offsetB := from.In(locB).Zone()
+	if offsetA != offsetB {
+		return false
+	}
+
+	changesA := offsetChanges(zoneTransitions(locA, from, to))
+	changesB := offsetChanges(zoneTransitions(locB, from, to))
+	if len(changesA) != len(changesB) {
+		return false
+	}
+	for i := range changesA {
+		if !changesA[i].At.Equal(changesB[i].At) || changesA[i].ToOffset != changesB[i].ToOffset {
+			return false
+		}
+	}
+	return true
+}
+
+// offsetChanges drops transitions that only change the abbreviation
+func offsetChanges(transitions []Transition) []Transition {
+	var changes []Transition
+	for _, t := range transitions {
+		if t.FromOffset != t.ToOffset {
+			changes = append(changes, t)
+		}
+	}
+	return changes
+}
+
+// canonicalZonesEnvVar, when true, applies WithCanonicalZones to the default
+// database
+const canonicalZonesEnvVar = "CITYTZ_CANONICAL_ZONES"
+
+// WithCanonicalZones rewrites every city's timezone to its canonical name
+// while loading, so that zones compare equal by name. The default database
+// is normalized the same way when CITYTZ_CANONICAL_ZONES is true.
+func WithCanonicalZones() Option {
+	return func(o *loadOptions) {
+		o.canonicalZones = true
+	}
+}
+
+// canonicalZonesFromEnv reports whether CITYTZ_CANONICAL_ZONES asks for
+// canonical zones in the default database
+func canonicalZonesFromEnv() (bool, error) {
+	value := os.Getenv(canonicalZonesEnvVar)
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", canonicalZonesEnvVar, err)
+	}
+	return enabled, nil
+}
+
+// canonicalizeZones rewrites the timezones of cities in place
+func canonicalizeZones(cities []CityData) {
+	for i := range cities {
+		cities[i].Timezone = CanonicalZone(cities[i].Timezone)
+	}
+}
@@ -0,0 +1,157 @@
+package citytimezones
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalZone(t *testing.T) {
+	tests := map[string]string{
+		"Asia/Calcutta":   "Asia/Kolkata",
+		"US/Central":      "America/Chicago",
+		"Europe/Kiev":     "Europe/Kyiv",
+		"America/Chicago": "America/Chicago",
+		"Not/AZone":       "Not/AZone",
+	}
+	for tz, want := range tests {
+		if got := CanonicalZone(tz); got != want {
+			t.Errorf("CanonicalZone(%q): expected %s, got %s", tz, want, got)
+		}
+	}
+}
+
+func TestZonesEquivalent(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !ZonesEquivalent("Asia/Calcutta", "Asia/Kolkata", from, to) {
+		t.Error("Expected an alias to be equivalent to its zone")
+	}
+	if !ZonesEquivalent("Europe/Amsterdam", "Europe/Brussels", from, to) {
+		t.Error("Expected zones with the same rules to be equivalent")
+	}
+	if ZonesEquivalent("America/Chicago", "America/Regina", from, to) {
+		t.Error("Expected a zone without DST to differ from one with DST")
+	}
+	if ZonesEquivalent("America/Chicago", "Not/AZone", from, to) {
+		t.Error("Expected unknown zones to not be equivalent")
+	}
+
+	// Europe/Volgograd moved from UTC+4 to UTC+3 in late 2020
+	if ZonesEquivalent("Europe/Moscow", "Europe/Volgograd", from, to) {
+		t.Error("Expected zones to differ over a range with diverging offsets")
+	}
+	if !ZonesEquivalent("Europe/Moscow", "Europe/Volgograd", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), to) {
+		t.Error("Expected zones to agree after they converged")
+	}
+}
+
+func TestLoadFrom_WithCanonicalZones(t *testing.T) {
+	data := `[{"city": "Kolkata", "lat": 22.57, "lng": 88.36, "country": "India", "timezone": "Asia/Calcutta"}]`
+
+	db, err := LoadFrom(strings.NewReader(data), WithCanonicalZones())
+	if err != nil {
+		t.Fatalf("Expected data to load, got error: %v", err)
+	}
+	if tz := db.Cities()[0].Timezone; tz != "Asia/Kolkata" {
+		t.Errorf("Expected Asia/Kolkata, got %s", tz)
+	}
+
+	db, err = LoadFrom(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected data to load, got error: %v", err)
+	}
+	if tz := db.Cities()[0].Timezone; tz != "Asia/Calcutta" {
+		t.Errorf("Expected timezone to be kept without the option, got %s", tz)
+	}
+}
+
+func TestReload_CanonicalZonesEnv(t *testing.T) {
+	restoreDefault(t)
+	path := filepath.Join(t.TempDir(), "cities.json")
+	writeTestCities(t, path, `[{"city": "Kolkata", "lat": 22.57, "lng": 88.36, "country": "India", "timezone": "Asia/Calcutta"}]`)
+
+	t.Setenv(canonicalZonesEnvVar, "true")
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	if tz := GetCityMapping()[0].Timezone; tz != "Asia/Kolkata" {
+		t.Errorf("Expected Asia/Kolkata, got %s", tz)
+	}
+
+	t.Setenv(canonicalZonesEnvVar, "false")
+	if err := Reload(path); err != nil {
+		t.Fatalf("Expected reload to succeed, got error: %v", err)
+	}
+	if tz := GetCityMapping()[0].Timezone; tz != "Asia/Calcutta" {
+		t.Errorf("Expected timezone to be kept, got %s", tz)
+	}
+
+	t.Setenv(canonicalZonesEnvVar, "sometimes")
+	if err := Reload(path); err == nil || !strings.Contains(err.Error(), canonicalZonesEnvVar) {
+		t.Errorf("Expected an error naming %s, got %v", canonicalZonesEnvVar, err)
+	}
+}
+
+func TestZoneLinks_Kinds(t *testing.T) {
+	kinds := map[string]string{}
+	for _, line := range strings.Split(embeddedZoneLinks, "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 && !strings.HasPrefix(line, "#") {
+			kinds[fields[0]] = fields[2]
+		}
+	}
+	if len(kinds) != len(loadZoneAliases()) {
+		t.Errorf("Expected every alias to have a kind, got %d of %d", len(kinds), len(loadZoneAliases()))
+	}
+	for alias, want := range map[string]string{"US/Central": "backward", "Asia/Calcutta": "backward", "Europe/Bratislava": "merged"} {
+		if kinds[alias] != want {
+			t.Errorf("Expected %s to be a %s link, got %q", alias, want, kinds[alias])
+		}
+	}
+}
+
+func TestCanonicalZone_LegacyAndCurrentNamesAgree(t *testing.T) {
+	// Renamed places from tzdata's backward file, legacy spelling first
+	renamed := map[string]string{
+		"Africa/Asmera":         "Africa/Asmara",
+		"America/Coral_Harbour": "America/Atikokan",
+		"America/Godthab":       "America/Nuuk",
+		"America/Buenos_Aires":  "America/Argentina/Buenos_Aires",
+		"America/Indianapolis":  "America/Indiana/Indianapolis",
+		"America/Louisville":    "America/Kentucky/Louisville",
+		"Asia/Calcutta":         "Asia/Kolkata",
+		"Asia/Katmandu":         "Asia/Kathmandu",
+		"Asia/Rangoon":          "Asia/Yangon",
+		"Asia/Saigon":           "Asia/Ho_Chi_Minh",
+		"Asia/Dacca":            "Asia/Dhaka",
+		"Asia/Thimbu":           "Asia/Thimphu",
+		"Asia/Ujung_Pandang":    "Asia/Makassar",
+		"Asia/Ulan_Bator":       "Asia/Ulaanbaatar",
+		"Asia/Macao":            "Asia/Macau",
+		"Asia/Ashkhabad":        "Asia/Ashgabat",
+		"Atlantic/Faeroe":       "Atlantic/Faroe",
+		"Europe/Kiev":           "Europe/Kyiv",
+		"Pacific/Ponape":        "Pacific/Pohnpei",
+		"Pacific/Truk":          "Pacific/Chuuk",
+		"Pacific/Enderbury":     "Pacific/Kanton",
+	}
+	for legacy, current := range renamed {
+		if got, want := CanonicalZone(legacy), CanonicalZone(current); got != want {
+			t.Errorf("Expected %s and %s to canonicalize alike, got %s and %s", legacy, current, got, want)
+		}
+	}
+}
+
+func TestZoneLinks_TargetsAreZones(t *testing.T) {
+	aliases := loadZoneAliases()
+	for alias, target := range aliases {
+		if _, ok := aliases[target]; ok {
+			t.Errorf("Expected %s to link to a zone, got the alias %s", alias, target)
+		}
+		if _, err := time.LoadLocation(target); err != nil {
+			t.Errorf("Expected %s's target %s to load, got %v", alias, target, err)
+		}
+	}
+}